@@ -0,0 +1,243 @@
+// File: api/v1alpha1/microfrontend_conversion.go
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"mfe-operator/api/v1beta1"
+)
+
+// hubFieldsAnnotation preserves v1beta1 fields that v1alpha1 cannot
+// represent, so that a round trip through v1alpha1 is lossless.
+const hubFieldsAnnotation = "platform.mycorp.com/v1beta1-fields"
+
+// hubFields are the v1beta1 spec fields without a v1alpha1 equivalent.
+type hubFields struct {
+	SharedModules *v1beta1.SharedModules `json:"sharedModules,omitempty"`
+	CachePolicy   *v1beta1.CachePolicy   `json:"cachePolicy,omitempty"`
+}
+
+// ConvertTo converts this MicroFrontend to the hub version (v1beta1).
+func (src *MicroFrontend) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.MicroFrontend)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	repository, tag, digest := splitReference(src.Spec.OCIArtifact)
+	backend, prefix := splitTarget(src.Spec.CDNTarget)
+	dst.Spec = v1beta1.MicroFrontendSpec{
+		Source: v1beta1.Source{OCI: v1beta1.OCISource{
+			Repository: repository,
+			Tag:        tag,
+			Digest:     digest,
+		}},
+		Target:            v1beta1.Target{Backend: backend, Path: prefix},
+		EntryPoint:        src.Spec.EntryPoint,
+		ExposedModules:    src.Spec.ExposedModules,
+		WorkspaceStrategy: src.Spec.WorkspaceStrategy,
+		SyncInterval:      src.Spec.SyncInterval,
+	}
+	if p := src.Spec.UpdatePolicy; p != nil {
+		dst.Spec.Source.OCI.UpdatePolicy = &v1beta1.UpdatePolicy{SemVer: p.SemVer, TagPattern: p.TagPattern, Interval: p.Interval}
+	}
+	if r := src.Spec.Rollout; r != nil {
+		dst.Spec.Rollout = &v1beta1.RolloutSpec{}
+		if r.Steps != nil {
+			dst.Spec.Rollout.Steps = make([]v1beta1.RolloutStep, len(r.Steps))
+			for i, step := range r.Steps {
+				dst.Spec.Rollout.Steps[i] = v1beta1.RolloutStep(step)
+			}
+		}
+	}
+	if c := src.Spec.SmokeCheck; c != nil {
+		dst.Spec.SmokeCheck = (*v1beta1.SmokeCheckSpec)(c)
+	}
+
+	if raw, ok := dst.Annotations[hubFieldsAnnotation]; ok {
+		var fields hubFields
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return fmt.Errorf("failed to decode %s annotation: %w", hubFieldsAnnotation, err)
+		}
+		dst.Spec.SharedModules = fields.SharedModules
+		dst.Spec.CachePolicy = fields.CachePolicy
+		delete(dst.Annotations, hubFieldsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Status = v1beta1.MicroFrontendStatus{
+		Synced:             src.Status.Synced,
+		LastSyncedAt:       src.Status.LastSyncedAt,
+		Message:            src.Status.Message,
+		Digest:             src.Status.Digest,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		EntryURL:           src.Status.EntryURL,
+		Conditions:         src.Status.Conditions,
+	}
+	if s := src.Status.UpdatePolicy; s != nil {
+		dst.Status.UpdatePolicy = (*v1beta1.UpdatePolicyStatus)(s)
+	}
+	if src.Status.SharedModules != nil {
+		dst.Status.SharedModules = make([]v1beta1.SharedModuleStatus, len(src.Status.SharedModules))
+		for i, m := range src.Status.SharedModules {
+			dst.Status.SharedModules[i] = v1beta1.SharedModuleStatus(m)
+		}
+	}
+	if s := src.Status.Attestations; s != nil {
+		dst.Status.Attestations = (*v1beta1.AttestationSummary)(s)
+	}
+	if s := src.Status.Rollout; s != nil {
+		dst.Status.Rollout = &v1beta1.RolloutStatus{
+			Phase:         s.Phase,
+			Stable:        v1beta1.RolloutVersion(s.Stable),
+			Canary:        (*v1beta1.RolloutVersion)(s.Canary),
+			Step:          s.Step,
+			Weight:        s.Weight,
+			StepStartedAt: s.StepStartedAt,
+		}
+	}
+	if s := src.Status.SmokeCheck; s != nil {
+		dst.Status.SmokeCheck = &v1beta1.SmokeCheckStatus{Digest: s.Digest, BaseURL: s.BaseURL, LastCheckedAt: s.LastCheckedAt}
+		if s.Files != nil {
+			dst.Status.SmokeCheck.Files = make([]v1beta1.SmokeCheckFile, len(s.Files))
+			for i, f := range s.Files {
+				dst.Status.SmokeCheck.Files[i] = v1beta1.SmokeCheckFile(f)
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this version.
+func (dst *MicroFrontend) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.MicroFrontend)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	oci := src.Spec.Source.OCI
+	dst.Spec = MicroFrontendSpec{
+		OCIArtifact:       joinReference(oci.Repository, oci.Tag, oci.Digest),
+		CDNTarget:         joinTarget(src.Spec.Target.Backend, src.Spec.Target.Path),
+		EntryPoint:        src.Spec.EntryPoint,
+		ExposedModules:    src.Spec.ExposedModules,
+		WorkspaceStrategy: src.Spec.WorkspaceStrategy,
+		SyncInterval:      src.Spec.SyncInterval,
+	}
+	if p := oci.UpdatePolicy; p != nil {
+		dst.Spec.UpdatePolicy = &UpdatePolicy{SemVer: p.SemVer, TagPattern: p.TagPattern, Interval: p.Interval}
+	}
+	if r := src.Spec.Rollout; r != nil {
+		dst.Spec.Rollout = &RolloutSpec{}
+		if r.Steps != nil {
+			dst.Spec.Rollout.Steps = make([]RolloutStep, len(r.Steps))
+			for i, step := range r.Steps {
+				dst.Spec.Rollout.Steps[i] = RolloutStep(step)
+			}
+		}
+	}
+	if c := src.Spec.SmokeCheck; c != nil {
+		dst.Spec.SmokeCheck = (*SmokeCheckSpec)(c)
+	}
+
+	delete(dst.Annotations, hubFieldsAnnotation)
+	if src.Spec.SharedModules != nil || src.Spec.CachePolicy != nil {
+		raw, err := json.Marshal(hubFields{SharedModules: src.Spec.SharedModules, CachePolicy: src.Spec.CachePolicy})
+		if err != nil {
+			return fmt.Errorf("failed to encode %s annotation: %w", hubFieldsAnnotation, err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[hubFieldsAnnotation] = string(raw)
+	}
+
+	dst.Status = MicroFrontendStatus{
+		Synced:             src.Status.Synced,
+		LastSyncedAt:       src.Status.LastSyncedAt,
+		Message:            src.Status.Message,
+		Digest:             src.Status.Digest,
+		ObservedGeneration: src.Status.ObservedGeneration,
+		EntryURL:           src.Status.EntryURL,
+		Conditions:         src.Status.Conditions,
+	}
+	if s := src.Status.UpdatePolicy; s != nil {
+		dst.Status.UpdatePolicy = (*UpdatePolicyStatus)(s)
+	}
+	if src.Status.SharedModules != nil {
+		dst.Status.SharedModules = make([]SharedModuleStatus, len(src.Status.SharedModules))
+		for i, m := range src.Status.SharedModules {
+			dst.Status.SharedModules[i] = SharedModuleStatus(m)
+		}
+	}
+	if s := src.Status.Attestations; s != nil {
+		dst.Status.Attestations = (*AttestationSummary)(s)
+	}
+	if s := src.Status.Rollout; s != nil {
+		dst.Status.Rollout = &RolloutStatus{
+			Phase:         s.Phase,
+			Stable:        RolloutVersion(s.Stable),
+			Canary:        (*RolloutVersion)(s.Canary),
+			Step:          s.Step,
+			Weight:        s.Weight,
+			StepStartedAt: s.StepStartedAt,
+		}
+	}
+	if s := src.Status.SmokeCheck; s != nil {
+		dst.Status.SmokeCheck = &SmokeCheckStatus{Digest: s.Digest, BaseURL: s.BaseURL, LastCheckedAt: s.LastCheckedAt}
+		if s.Files != nil {
+			dst.Status.SmokeCheck.Files = make([]SmokeCheckFile, len(s.Files))
+			for i, f := range s.Files {
+				dst.Status.SmokeCheck.Files[i] = SmokeCheckFile(f)
+			}
+		}
+	}
+	return nil
+}
+
+// splitReference splits an OCI reference into repository, tag and digest.
+// A reference that would not be rebuilt verbatim is kept whole in the
+// repository so that conversion never alters it.
+func splitReference(ref string) (repository, tag, digest string) {
+	repository = ref
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, digest = repository[:i], repository[i+1:]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	if joinReference(repository, tag, digest) != ref {
+		return ref, "", ""
+	}
+	return repository, tag, digest
+}
+
+func joinReference(repository, tag, digest string) string {
+	ref := repository
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if digest != "" {
+		ref += "@" + digest
+	}
+	return ref
+}
+
+// splitTarget splits a "<backend>/<path>" CDN target, keeping targets that
+// would not be rebuilt verbatim whole in the backend.
+func splitTarget(target string) (backend, prefix string) {
+	backend, prefix, _ = strings.Cut(target, "/")
+	if joinTarget(backend, prefix) != target {
+		return target, ""
+	}
+	return backend, prefix
+}
+
+func joinTarget(backend, prefix string) string {
+	if prefix == "" {
+		return backend
+	}
+	return backend + "/" + prefix
+}
@@ -0,0 +1,126 @@
+// File: api/v1alpha1/microfrontend_conversion_test.go
+package v1alpha1_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/api/v1beta1"
+)
+
+const fuzzIterations = 500
+
+// randString returns a non-empty string over alphabet.
+func randString(c fuzz.Continue, alphabet string) string {
+	b := make([]byte, 1+c.Intn(12))
+	for i := range b {
+		b[i] = alphabet[c.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// newFuzzer returns a fuzzer that only produces hub sources and targets a
+// user could write, i.e. whose parts contain no separators other than the
+// ':' of a registry port.
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.3).RandSource(rand.NewSource(seed)).Funcs(
+		func(s *v1beta1.OCISource, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+			s.Repository = randString(c, "abcdefghijklmnopqrstuvwxyz0123456789./-")
+			if c.RandBool() {
+				// A registry host with a port puts a ':' before the path
+				s.Repository = randString(c, "abcdefghijklmnopqrstuvwxyz0123456789.-") + ":" +
+					randString(c, "0123456789") + "/" + s.Repository
+			}
+			if s.Tag != "" {
+				s.Tag = randString(c, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789._-")
+			}
+			if s.Digest != "" {
+				s.Digest = "sha256:" + randString(c, "0123456789abcdef")
+			}
+		},
+		func(t *v1beta1.Target, c fuzz.Continue) {
+			t.Backend = randString(c, "abcdefghijklmnopqrstuvwxyz-")
+			c.Fuzz(&t.Path)
+		},
+	)
+}
+
+func TestSpokeRoundTrip(t *testing.T) {
+	f := newFuzzer(1)
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1alpha1.MicroFrontend{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "team-a"}}
+		f.Fuzz(&original.Spec)
+		f.Fuzz(&original.Status)
+
+		hub := &v1beta1.MicroFrontend{}
+		require.NoError(t, original.ConvertTo(hub))
+		got := &v1alpha1.MicroFrontend{}
+		require.NoError(t, got.ConvertFrom(hub))
+		assert.Equal(t, original, got)
+	}
+}
+
+func TestHubRoundTrip(t *testing.T) {
+	f := newFuzzer(2)
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1beta1.MicroFrontend{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "team-a"}}
+		f.Fuzz(&original.Spec)
+		f.Fuzz(&original.Status)
+
+		spoke := &v1alpha1.MicroFrontend{}
+		require.NoError(t, spoke.ConvertFrom(original))
+		got := &v1beta1.MicroFrontend{}
+		require.NoError(t, spoke.ConvertTo(got))
+		assert.Equal(t, original, got)
+	}
+}
+
+func TestConvertToSplitsReferenceAndTarget(t *testing.T) {
+	src := &v1alpha1.MicroFrontend{Spec: v1alpha1.MicroFrontendSpec{
+		OCIArtifact: "localhost:5000/mfe/checkout:v1.2.0@sha256:abc",
+		CDNTarget:   "primary/apps/checkout",
+	}}
+	dst := &v1beta1.MicroFrontend{}
+	require.NoError(t, src.ConvertTo(dst))
+
+	assert.Equal(t, v1beta1.OCISource{Repository: "localhost:5000/mfe/checkout", Tag: "v1.2.0", Digest: "sha256:abc"}, dst.Spec.Source.OCI)
+	assert.Equal(t, v1beta1.Target{Backend: "primary", Path: "apps/checkout"}, dst.Spec.Target)
+}
+
+func TestReferenceRoundTrip(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	tests := []struct {
+		ref  string
+		want v1beta1.OCISource
+	}{
+		{"ghcr.io/mycorp/checkout:v1.2.0", v1beta1.OCISource{Repository: "ghcr.io/mycorp/checkout", Tag: "v1.2.0"}},
+		{"ghcr.io/mycorp/checkout@" + digest, v1beta1.OCISource{Repository: "ghcr.io/mycorp/checkout", Digest: digest}},
+		{"ghcr.io/mycorp/checkout:v1.2.0@" + digest, v1beta1.OCISource{Repository: "ghcr.io/mycorp/checkout", Tag: "v1.2.0", Digest: digest}},
+		{"localhost:5000/checkout", v1beta1.OCISource{Repository: "localhost:5000/checkout"}},
+		{"localhost:5000/mfe/checkout:latest@" + digest, v1beta1.OCISource{Repository: "localhost:5000/mfe/checkout", Tag: "latest", Digest: digest}},
+		{"registry.example.com:443/mfe/checkout:v2", v1beta1.OCISource{Repository: "registry.example.com:443/mfe/checkout", Tag: "v2"}},
+		// References that would not be rebuilt verbatim stay whole
+		{"ghcr.io/mycorp/checkout:", v1beta1.OCISource{Repository: "ghcr.io/mycorp/checkout:"}},
+		{"ghcr.io/mycorp/checkout@", v1beta1.OCISource{Repository: "ghcr.io/mycorp/checkout@"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			original := &v1alpha1.MicroFrontend{Spec: v1alpha1.MicroFrontendSpec{OCIArtifact: tt.ref, CDNTarget: "primary/apps/checkout"}}
+			hub := &v1beta1.MicroFrontend{}
+			require.NoError(t, original.ConvertTo(hub))
+			assert.Equal(t, tt.want, hub.Spec.Source.OCI)
+
+			got := &v1alpha1.MicroFrontend{}
+			require.NoError(t, got.ConvertFrom(hub))
+			assert.Equal(t, tt.ref, got.Spec.OCIArtifact)
+		})
+	}
+}
@@ -3,16 +3,110 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // MicroFrontendSpec defines the desired state of MicroFrontend
 type MicroFrontendSpec struct {
-	OCIArtifact string   `json:"ociArtifact"`
-	CDNTarget   string   `json:"cdnTarget"`
-	EntryPoint  string   `json:"entryPoint"`
-	ExposedModules []string `json:"exposedModules"`
+	// OCIArtifact is the bundle reference, e.g. "ghcr.io/mycorp/checkout:v1.2.0".
+	//+kubebuilder:validation:MinLength=1
+	OCIArtifact string `json:"ociArtifact"`
+
+	// CDNTarget is "<backend>/<path>", naming a backend from the operator's
+	// CDN configuration and the prefix the bundle is published under. The
+	// prefix may not contain "." or ".." segments.
+	//+kubebuilder:validation:Pattern=`^[^/]+(/+([^/.][^/]*|\.[^/.][^/]*|\.\.[^/]+))+/*$`
+	CDNTarget string `json:"cdnTarget"`
+
+	// EntryPoint is the Module Federation container entry, relative to the
+	// bundle root.
+	//+kubebuilder:default=remoteEntry.js
+	//+kubebuilder:validation:Pattern=`^[^/\\]`
+	//+optional
+	EntryPoint string `json:"entryPoint,omitempty"`
+
+	// ExposedModules the bundle must expose, e.g. "./Cart".
+	//+listType=set
+	//+kubebuilder:validation:items:Pattern=`^\./[A-Za-z0-9_-]+(/[A-Za-z0-9_.-]+)*$`
+	//+optional
+	ExposedModules []string `json:"exposedModules,omitempty"`
+
+	// WorkspaceStrategy overrides the operator's --workspace-strategy for
+	// this MicroFrontend.
+	//+kubebuilder:validation:Enum=IsolatedTempDir;UseCRName;UseUUID
+	//+optional
+	WorkspaceStrategy string `json:"workspaceStrategy,omitempty"`
+
+	// UpdatePolicy watches the OCIArtifact repository and deploys the newest
+	// tag it accepts instead of the tag in OCIArtifact.
+	//+optional
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// SyncInterval overrides the operator's --resync-period for this
+	// MicroFrontend.
+	//+optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+
+	// Rollout publishes each new bundle next to the current one and shifts
+	// traffic to it step by step instead of all at once.
+	//+optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// SmokeCheck configures the checks run against the CDN after each
+	// publish.
+	//+optional
+	SmokeCheck *SmokeCheckSpec `json:"smokeCheck,omitempty"`
+}
+
+// RolloutSpec configures a progressive rollout
+type RolloutSpec struct {
+	// Steps are the weights a new version receives, in order, before it
+	// becomes the stable version.
+	//+kubebuilder:validation:MinItems=1
+	Steps []RolloutStep `json:"steps"`
+}
+
+// RolloutStep sends Weight percent of users to the new version
+type RolloutStep struct {
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+
+	// Pause is how long the step lasts. Without it the step lasts until the
+	// rollout annotation is set to "promote".
+	//+optional
+	Pause *metav1.Duration `json:"pause,omitempty"`
+}
+
+// SmokeCheckSpec configures the HTTP checks of published files
+type SmokeCheckSpec struct {
+	// BaseURL the published files are fetched from, overriding the CDN
+	// backend's public URL, e.g. to bypass a cache in front of the target.
+	//+kubebuilder:validation:Pattern=`^https?://`
+	//+optional
+	BaseURL string `json:"baseURL,omitempty"`
+
+	// Chunks is how many files besides the entry point are fetched.
+	// Defaults to 3.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Chunks *int32 `json:"chunks,omitempty"`
+}
+
+// UpdatePolicy selects which tag of the OCIArtifact repository to deploy
+type UpdatePolicy struct {
+	// SemVer is a semver constraint (e.g. "^2.3"); the highest matching
+	// version wins.
+	//+optional
+	SemVer string `json:"semver,omitempty"`
+
+	// TagPattern is a regular expression tags must match. Without SemVer the
+	// lexically greatest matching tag wins.
+	//+optional
+	TagPattern string `json:"tagPattern,omitempty"`
+
+	// Interval between registry polls. Defaults to 5m.
+	//+optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
 }
 
 // MicroFrontendStatus defines the observed state of MicroFrontend
@@ -20,10 +114,192 @@ type MicroFrontendStatus struct {
 	Synced       bool   `json:"synced"`
 	LastSyncedAt string `json:"lastSyncedAt,omitempty"`
 	Message      string `json:"message,omitempty"`
+
+	// Digest is the manifest digest of the bundle last published to the CDN.
+	//+optional
+	Digest string `json:"digest,omitempty"`
+	// ObservedGeneration is the spec generation Digest was published for.
+	//+optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// EntryURL is the public URL of the published entry point.
+	//+optional
+	EntryURL string `json:"entryURL,omitempty"`
+
+	// UpdatePolicy records the tag most recently chosen by the update policy.
+	//+optional
+	UpdatePolicy *UpdatePolicyStatus `json:"updatePolicy,omitempty"`
+
+	// SharedModules lists the bundle's shared dependencies and where each was
+	// published under vendor/.
+	//+optional
+	SharedModules []SharedModuleStatus `json:"sharedModules,omitempty"`
+
+	// Attestations summarizes the SBOM and provenance attached to the bundle.
+	//+optional
+	Attestations *AttestationSummary `json:"attestations,omitempty"`
+
+	// Rollout tracks the versions served while Spec.Rollout is set.
+	//+optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// SmokeCheck records the files checked on the CDN after the last
+	// publish; the outcome is the Ready condition.
+	//+optional
+	SmokeCheck *SmokeCheckStatus `json:"smokeCheck,omitempty"`
+
+	// Conditions report the outcome of each pipeline stage.
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// UpdatePolicyStatus records the outcome of the registry watch
+type UpdatePolicyStatus struct {
+	// ObservedGeneration is the spec generation the tag was chosen for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	Tag            string `json:"tag,omitempty"`
+	Digest         string `json:"digest,omitempty"`
+	LastCheckedAt  string `json:"lastCheckedAt,omitempty"`
+	LastPromotedAt string `json:"lastPromotedAt,omitempty"`
 }
 
+// SharedModuleStatus records a shared dependency of the bundle
+type SharedModuleStatus struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// RequiredVersion is the semver range the bundle accepts.
+	//+optional
+	RequiredVersion string `json:"requiredVersion,omitempty"`
+	//+optional
+	Singleton bool `json:"singleton,omitempty"`
+
+	// Path is the CDN prefix the files were published under. It is empty
+	// for modules that were not published, e.g. eager ones.
+	//+optional
+	Path string `json:"path,omitempty"`
+	// Files are the published files, relative to Path.
+	Files []string `json:"files,omitempty"`
+	// ContentHash identifies the content of Files, see SharedModuleVersion.
+	//+optional
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// Rollout phases reported in RolloutStatus.Phase.
+const (
+	RolloutProgressing = "Progressing"
+	RolloutPromoted    = "Promoted"
+	RolloutAborted     = "Aborted"
+)
+
+// RolloutStatus records the progress of a rollout
+type RolloutStatus struct {
+	// Phase is Progressing, Promoted or Aborted.
+	Phase string `json:"phase"`
+
+	// Stable is the version that receives the remaining traffic.
+	Stable RolloutVersion `json:"stable"`
+	// Canary is the version being rolled out.
+	//+optional
+	Canary *RolloutVersion `json:"canary,omitempty"`
+
+	// Step is the index of the current step in Spec.Rollout.Steps.
+	//+optional
+	Step int32 `json:"step,omitempty"`
+	// Weight is the percentage of users served Canary.
+	//+optional
+	Weight int32 `json:"weight,omitempty"`
+	//+optional
+	StepStartedAt string `json:"stepStartedAt,omitempty"`
+}
+
+// RolloutVersion is a published version of the bundle
+type RolloutVersion struct {
+	Digest   string `json:"digest"`
+	EntryURL string `json:"entryURL"`
+	// Tag is the tag the version was deployed from; empty when it was
+	// referenced by digest or is not known.
+	//+optional
+	Tag string `json:"tag,omitempty"`
+	// SharedModules lists the vendor/ paths of the shared modules the
+	// version loads, so they are kept while it receives traffic.
+	//+optional
+	SharedModules []string `json:"sharedModules,omitempty"`
+}
+
+// SmokeCheckStatus lists the files fetched to check a published bundle
+type SmokeCheckStatus struct {
+	// Digest is the bundle the files belong to.
+	Digest string `json:"digest"`
+	// BaseURL is where the files are fetched from; empty when neither the
+	// spec nor the CDN backend provides one.
+	//+optional
+	BaseURL string `json:"baseURL,omitempty"`
+	//+optional
+	Files []SmokeCheckFile `json:"files,omitempty"`
+	//+optional
+	LastCheckedAt string `json:"lastCheckedAt,omitempty"`
+}
+
+// SmokeCheckFile is a published file and the content it must be served with
+type SmokeCheckFile struct {
+	// Path is relative to BaseURL.
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// AttestationSummary summarizes the attestations attached to a bundle
+type AttestationSummary struct {
+	// SBOMFormat is "spdx" or "cyclonedx", or empty when no SBOM was found.
+	SBOMFormat   string `json:"sbomFormat,omitempty"`
+	PackageCount int    `json:"packageCount,omitempty"`
+
+	BuilderID        string `json:"builderID,omitempty"`
+	SourceRepository string `json:"sourceRepository,omitempty"`
+	SourceCommit     string `json:"sourceCommit,omitempty"`
+}
+
+// Condition types reported in MicroFrontendStatus.Conditions
+const (
+	// ConditionVerified reports whether the bundle's signatures satisfy the
+	// VerificationPolicies that apply to it.
+	ConditionVerified = "Verified"
+	// ConditionAttested reports whether the bundle's SBOM and provenance
+	// satisfy the VerificationPolicies that apply to it.
+	ConditionAttested = "Attested"
+	// ConditionConflict is true when another MicroFrontend claimed an
+	// overlapping CDN prefix first; the bundle is then not published.
+	ConditionConflict = "Conflict"
+	// ConditionModulesExposed reports whether the bundle exposes every module
+	// listed in Spec.ExposedModules.
+	ConditionModulesExposed = "ModulesExposed"
+	// ConditionReady reports whether what the operator publishes for the
+	// object is complete and usable.
+	ConditionReady = "Ready"
+)
+
+// RemoteNameAnnotation overrides the Module Federation remote name a
+// MicroFrontend is listed under in generated remote catalogs. It defaults
+// to the object name.
+const RemoteNameAnnotation = "platform.mycorp.com/remote-name"
+
+// RolloutAnnotation controls a progressing rollout: "promote" ends the
+// current step and "abort" sends all traffic back to the stable version.
+// The operator removes the annotation once it has acted on it.
+const RolloutAnnotation = "platform.mycorp.com/rollout"
+
+// Values of RolloutAnnotation
+const (
+	RolloutPromote = "promote"
+	RolloutAbort   = "abort"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mfe
+//+kubebuilder:printcolumn:name="Synced",type=boolean,JSONPath=`.status.synced`
+//+kubebuilder:printcolumn:name="Digest",type=string,JSONPath=`.status.digest`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // MicroFrontend is the Schema for the microfrontends API
 type MicroFrontend struct {
@@ -46,11 +322,3 @@ type MicroFrontendList struct {
 func init() {
 	SchemeBuilder.Register(&MicroFrontend{}, &MicroFrontendList{})
 }
-
-func (in *MicroFrontend) GetObjectKind() schema.ObjectKind {
-	return &in.TypeMeta
-}
-
-func (in *MicroFrontend) DeepCopyObject() runtime.Object {
-	return in.DeepCopy()
-}
@@ -0,0 +1,125 @@
+// File: api/v1alpha1/microfrontendhost_types.go
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MicroFrontendHostSpec defines the remotes a shell application composes
+type MicroFrontendHostSpec struct {
+	// CDNTarget is the "<backend>/<prefix>" the host manifest is published
+	// under.
+	//+kubebuilder:validation:Pattern=`^[^/]+(/+([^/.][^/]*|\.[^/.][^/]*|\.\.[^/]+))+/*$`
+	CDNTarget string `json:"cdnTarget"`
+
+	// Selector selects MicroFrontends in the host's namespace by label.
+	//+optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Remotes selects MicroFrontends by name, optionally constraining their
+	// version. A remote also matched by Selector takes its constraint from
+	// here.
+	//+optional
+	Remotes []HostRemote `json:"remotes,omitempty"`
+
+	// Singletons are the shared modules the host provides as singletons.
+	// Remotes whose shared modules do not accept these versions are
+	// reported as incompatible and left out of the manifest.
+	//+optional
+	Singletons []HostSingleton `json:"singletons,omitempty"`
+}
+
+// HostRemote selects a MicroFrontend by name
+type HostRemote struct {
+	// MicroFrontend is the name of a MicroFrontend in the host's namespace.
+	//+kubebuilder:validation:MinLength=1
+	MicroFrontend string `json:"microFrontend"`
+
+	// Version pins the published bundle to a digest ("sha256:...") or
+	// constrains its tag to a semver range, e.g. "^1.4.0".
+	//+optional
+	Version string `json:"version,omitempty"`
+}
+
+// HostSingleton is a shared module version provided by the host
+type HostSingleton struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Remote resolution states reported in HostRemoteStatus.State.
+const (
+	RemoteResolved     = "Resolved"
+	RemoteMissing      = "Missing"
+	RemoteIncompatible = "Incompatible"
+)
+
+// MicroFrontendHostStatus defines the observed state of MicroFrontendHost
+type MicroFrontendHostStatus struct {
+	//+optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ManifestURL is the public URL of the published host manifest.
+	//+optional
+	ManifestURL string `json:"manifestURL,omitempty"`
+	// ManifestHash identifies the content last published.
+	//+optional
+	ManifestHash string `json:"manifestHash,omitempty"`
+	//+optional
+	PublishedAt string `json:"publishedAt,omitempty"`
+
+	// Remotes reports how each selected MicroFrontend was resolved.
+	//+optional
+	Remotes []HostRemoteStatus `json:"remotes,omitempty"`
+
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// HostRemoteStatus reports the resolution of one remote
+type HostRemoteStatus struct {
+	// Name is the remote name in the host manifest.
+	Name          string `json:"name"`
+	MicroFrontend string `json:"microFrontend"`
+	// State is Resolved, Missing or Incompatible.
+	State string `json:"state"`
+	//+optional
+	URL string `json:"url,omitempty"`
+	//+optional
+	Version string `json:"version,omitempty"`
+	//+optional
+	Digest string `json:"digest,omitempty"`
+	//+optional
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mfehost
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Manifest",type=string,JSONPath=`.status.manifestURL`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MicroFrontendHost is a shell application that composes MicroFrontends.
+// The operator publishes a manifest mapping each remote to its entry URL,
+// together with the singletons the host provides.
+type MicroFrontendHost struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MicroFrontendHostSpec   `json:"spec,omitempty"`
+	Status MicroFrontendHostStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MicroFrontendHostList contains a list of MicroFrontendHost
+type MicroFrontendHostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MicroFrontendHost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MicroFrontendHost{}, &MicroFrontendHostList{})
+}
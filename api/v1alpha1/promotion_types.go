@@ -0,0 +1,131 @@
+// File: api/v1alpha1/promotion_types.go
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PromotionSpec defines the stages a MicroFrontend's bundle is promoted
+// through
+type PromotionSpec struct {
+	// MicroFrontend is the name of the MicroFrontend, in the Promotion's
+	// namespace, whose published digest is promoted.
+	//+kubebuilder:validation:MinLength=1
+	MicroFrontend string `json:"microFrontend"`
+
+	// Stages are published in order; a stage starts once the previous one
+	// has succeeded with the same digest.
+	//+kubebuilder:validation:MinItems=1
+	//+listType=map
+	//+listMapKey=name
+	Stages []PromotionStage `json:"stages"`
+}
+
+// PromotionStage is an environment the bundle is published to
+type PromotionStage struct {
+	// Name identifies the stage, e.g. "staging".
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	//+kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// CDNTarget is the "<backend>/<path>" the stage publishes to.
+	//+kubebuilder:validation:Pattern=`^[^/]+(/+([^/.][^/]*|\.[^/.][^/]*|\.\.[^/]+))+/*$`
+	CDNTarget string `json:"cdnTarget"`
+
+	// RequireApproval holds the stage until the PromotionApproveAnnotation
+	// names it.
+	//+optional
+	RequireApproval bool `json:"requireApproval,omitempty"`
+
+	// SmokeCheck configures the checks that must pass before the stage
+	// succeeds.
+	//+optional
+	SmokeCheck *SmokeCheckSpec `json:"smokeCheck,omitempty"`
+}
+
+// PromotionApproveAnnotation approves a stage for a digest, given as
+// "<stage>@<digest>". It only takes effect while that digest is being
+// promoted; the operator removes it once it has acted on it.
+const PromotionApproveAnnotation = "platform.mycorp.com/approve"
+
+// PromotionStageLabel is set on the MicroFrontends a Promotion creates to
+// the name of their stage.
+const PromotionStageLabel = "platform.mycorp.com/promotion-stage"
+
+// Stage phases reported in PromotionStageStatus.Phase.
+const (
+	StagePending            = "Pending"
+	StageWaitingForApproval = "WaitingForApproval"
+	StageProgressing        = "Progressing"
+	StageSucceeded          = "Succeeded"
+)
+
+// PromotionStatus defines the observed state of Promotion
+type PromotionStatus struct {
+	// Digest is the bundle being promoted.
+	//+optional
+	Digest string `json:"digest,omitempty"`
+	// CurrentStage is the first stage that has not succeeded with Digest.
+	//+optional
+	CurrentStage string `json:"currentStage,omitempty"`
+	// Stages reports each stage of Spec.Stages.
+	//+optional
+	Stages []PromotionStageStatus `json:"stages,omitempty"`
+	//+optional
+	Message string `json:"message,omitempty"`
+}
+
+// PromotionStageStatus records the progress of one stage
+type PromotionStageStatus struct {
+	Name string `json:"name"`
+	// Phase is Pending, WaitingForApproval, Progressing or Succeeded.
+	Phase string `json:"phase"`
+	// Digest is the bundle the stage last succeeded with.
+	//+optional
+	Digest string `json:"digest,omitempty"`
+	// MicroFrontend publishes the stage.
+	//+optional
+	MicroFrontend string `json:"microFrontend,omitempty"`
+	//+optional
+	ApprovedAt string `json:"approvedAt,omitempty"`
+	// ApprovedDigest is the bundle the stage was approved for.
+	//+optional
+	ApprovedDigest string `json:"approvedDigest,omitempty"`
+	//+optional
+	StartedAt string `json:"startedAt,omitempty"`
+	//+optional
+	CompletedAt string `json:"completedAt,omitempty"`
+	//+optional
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="MicroFrontend",type=string,JSONPath=`.spec.microFrontend`
+//+kubebuilder:printcolumn:name="Stage",type=string,JSONPath=`.status.currentStage`
+//+kubebuilder:printcolumn:name="Digest",type=string,JSONPath=`.status.digest`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Promotion publishes the digest a MicroFrontend last published to a
+// sequence of further CDN targets, e.g. staging and then production. Each
+// stage is published by a MicroFrontend the Promotion owns.
+type Promotion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PromotionSpec   `json:"spec,omitempty"`
+	Status PromotionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PromotionList contains a list of Promotion
+type PromotionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Promotion `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Promotion{}, &PromotionList{})
+}
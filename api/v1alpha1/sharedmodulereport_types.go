@@ -0,0 +1,75 @@
+// File: api/v1alpha1/sharedmodulereport_types.go
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedModuleReportName is the name of the report the operator maintains.
+const SharedModuleReportName = "cluster"
+
+// SharedModuleReportStatus summarizes the shared module versions in use
+// across all MicroFrontends
+type SharedModuleReportStatus struct {
+	//+optional
+	LastAnalyzedAt string `json:"lastAnalyzedAt,omitempty"`
+	// MicroFrontends is the number of MicroFrontends analyzed.
+	MicroFrontends int `json:"microFrontends"`
+
+	// SingletonConflicts are singleton modules in use at incompatible major
+	// versions. Only one copy is loaded at runtime, so consumers built
+	// against the others may break.
+	//+optional
+	SingletonConflicts []SharedModuleSkew `json:"singletonConflicts,omitempty"`
+
+	// Fragmented lists the modules in use at more than one version, most
+	// fragmented first.
+	//+optional
+	Fragmented []SharedModuleSkew `json:"fragmented,omitempty"`
+}
+
+// SharedModuleSkew describes the versions of a shared module in use
+type SharedModuleSkew struct {
+	Name string `json:"name"`
+	//+optional
+	Singleton bool `json:"singleton,omitempty"`
+	// MajorVersions is the number of incompatible release lines in use.
+	MajorVersions int                      `json:"majorVersions"`
+	Versions      []SharedModuleVersionUse `json:"versions"`
+}
+
+// SharedModuleVersionUse lists the MicroFrontends using a module version
+type SharedModuleVersionUse struct {
+	Version string `json:"version"`
+	// MicroFrontends are given as namespace/name.
+	MicroFrontends []string `json:"microFrontends"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="MicroFrontends",type=integer,JSONPath=`.status.microFrontends`
+//+kubebuilder:printcolumn:name="Analyzed",type=string,JSONPath=`.status.lastAnalyzedAt`
+
+// SharedModuleReport is the operator's analysis of shared dependency version
+// skew across all MicroFrontends. A single report named "cluster" is kept up
+// to date.
+type SharedModuleReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status SharedModuleReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SharedModuleReportList contains a list of SharedModuleReport
+type SharedModuleReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedModuleReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedModuleReport{}, &SharedModuleReportList{})
+}
@@ -0,0 +1,94 @@
+// File: api/v1alpha1/sharedmoduleversion_types.go
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedModuleVersionSpec identifies a shared package version on a CDN backend
+type SharedModuleVersionSpec struct {
+	// Backend is the CDN backend the version is published to.
+	Backend string `json:"backend"`
+	// Package is the npm package name, e.g. "react" or "@mui/material".
+	Package string `json:"package"`
+	Version string `json:"version"`
+	// Path is the CDN prefix the files are published under.
+	Path string `json:"path"`
+}
+
+// SharedModuleVersionStatus records what is published and who uses it
+type SharedModuleVersionStatus struct {
+	// ContentHash identifies the published files; bundles whose copy of the
+	// version has the same hash reuse them instead of uploading again. It is
+	// claimed before the files are uploaded.
+	//+optional
+	ContentHash string `json:"contentHash,omitempty"`
+	// Files are the published files, relative to Spec.Path.
+	//+optional
+	Files []string `json:"files,omitempty"`
+	// PublishedAt is when the files were uploaded. It is empty while the
+	// upload for a claimed ContentHash has not completed.
+	//+optional
+	PublishedAt string `json:"publishedAt,omitempty"`
+
+	// References are the MicroFrontends that load this version.
+	//+optional
+	References []SharedModuleReference `json:"references,omitempty"`
+
+	// Collisions are MicroFrontends that ship the same version with
+	// different content. Their copy is not published.
+	//+optional
+	Collisions []SharedModuleCollision `json:"collisions,omitempty"`
+
+	// UnreferencedSince is when the vendor garbage collector first found no
+	// MicroFrontend using this version. The files are deleted once the grace
+	// period has passed.
+	//+optional
+	UnreferencedSince string `json:"unreferencedSince,omitempty"`
+}
+
+// SharedModuleReference names a MicroFrontend
+type SharedModuleReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// SharedModuleCollision records a MicroFrontend whose copy of a version
+// differs from the published one
+type SharedModuleCollision struct {
+	SharedModuleReference `json:",inline"`
+
+	ContentHash string `json:"contentHash"`
+	DetectedAt  string `json:"detectedAt,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,shortName=smv
+//+kubebuilder:printcolumn:name="Package",type=string,JSONPath=`.spec.package`
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+//+kubebuilder:printcolumn:name="Backend",type=string,JSONPath=`.spec.backend`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SharedModuleVersion is the operator's registry entry for one version of a
+// shared package on a CDN backend
+type SharedModuleVersion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SharedModuleVersionSpec   `json:"spec,omitempty"`
+	Status SharedModuleVersionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SharedModuleVersionList contains a list of SharedModuleVersion
+type SharedModuleVersionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedModuleVersion `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedModuleVersion{}, &SharedModuleVersionList{})
+}
@@ -0,0 +1,106 @@
+// File: api/v1alpha1/verificationpolicy_types.go
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VerificationPolicySpec defines which signatures a bundle must carry before
+// it is published
+type VerificationPolicySpec struct {
+	// Repositories the policy applies to, as glob patterns over
+	// "<registry>/<repository>" (e.g. "ghcr.io/mycorp/*"). "*" matches any
+	// characters including "/", so nested repositories are covered. Empty
+	// matches all.
+	//+optional
+	Repositories []string `json:"repositories,omitempty"`
+
+	// Cosign accepts cosign signatures attached as OCI referrers.
+	//+optional
+	Cosign *SignatureTrust `json:"cosign,omitempty"`
+
+	// Notation accepts Notary Project (JWS) signatures attached as OCI referrers.
+	//+optional
+	Notation *SignatureTrust `json:"notation,omitempty"`
+
+	// Attestations requires SBOM and provenance attestations attached as OCI
+	// referrers.
+	//+optional
+	Attestations *AttestationRequirements `json:"attestations,omitempty"`
+}
+
+// AttestationRequirements lists the attestations a bundle must carry
+type AttestationRequirements struct {
+	// RequireSBOM refuses bundles without an SPDX or CycloneDX SBOM.
+	//+optional
+	RequireSBOM bool `json:"requireSBOM,omitempty"`
+
+	// AllowedBuilders are regular expressions matched against the whole SLSA
+	// provenance builder ID. When set, every provenance statement attached
+	// to the bundle must be signed by a signer the policy's cosign trust
+	// accepts and name a matching builder; bundles without provenance are
+	// refused.
+	//+optional
+	AllowedBuilders []string `json:"allowedBuilders,omitempty"`
+}
+
+// SignatureTrust lists the signers trusted by one signature scheme
+type SignatureTrust struct {
+	// PublicKeys are PEM-encoded public keys. Only used by cosign.
+	//+optional
+	PublicKeys []string `json:"publicKeys,omitempty"`
+
+	// RootCertificates are PEM-encoded CA certificates that signing
+	// certificates must chain to.
+	//+optional
+	RootCertificates []string `json:"rootCertificates,omitempty"`
+
+	// Identities restrict which certificates are trusted. When empty, any
+	// certificate chaining to RootCertificates is trusted.
+	//+optional
+	Identities []CertificateIdentity `json:"identities,omitempty"`
+
+	// TransparencyLogKeys are PEM-encoded public keys of the Rekor
+	// transparency log. Only used by cosign: a certificate signature must
+	// carry a log entry signed by one of them, and the certificate is
+	// checked at the time the entry was logged. Certificate signatures are
+	// rejected when this is empty.
+	//+optional
+	TransparencyLogKeys []string `json:"transparencyLogKeys,omitempty"`
+}
+
+// CertificateIdentity matches a signing certificate
+type CertificateIdentity struct {
+	// Subject is a regular expression matched against the whole of each of
+	// the certificate's SAN emails and URIs. For Notation it is also matched
+	// against the subject DN in RFC 2253 form, e.g. "CN=release,O=MyCorp".
+	Subject string `json:"subject"`
+
+	// Issuer, when set, must equal the OIDC issuer recorded by Fulcio.
+	//+optional
+	Issuer string `json:"issuer,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// VerificationPolicy is the Schema for the verificationpolicies API
+type VerificationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VerificationPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// VerificationPolicyList contains a list of VerificationPolicy
+type VerificationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerificationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VerificationPolicy{}, &VerificationPolicyList{})
+}
@@ -0,0 +1,1090 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttestationRequirements) DeepCopyInto(out *AttestationRequirements) {
+	*out = *in
+	if in.AllowedBuilders != nil {
+		in, out := &in.AllowedBuilders, &out.AllowedBuilders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttestationRequirements.
+func (in *AttestationRequirements) DeepCopy() *AttestationRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(AttestationRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttestationSummary) DeepCopyInto(out *AttestationSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttestationSummary.
+func (in *AttestationSummary) DeepCopy() *AttestationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(AttestationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateIdentity) DeepCopyInto(out *CertificateIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateIdentity.
+func (in *CertificateIdentity) DeepCopy() *CertificateIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostRemote) DeepCopyInto(out *HostRemote) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostRemote.
+func (in *HostRemote) DeepCopy() *HostRemote {
+	if in == nil {
+		return nil
+	}
+	out := new(HostRemote)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostRemoteStatus) DeepCopyInto(out *HostRemoteStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostRemoteStatus.
+func (in *HostRemoteStatus) DeepCopy() *HostRemoteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HostRemoteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostSingleton) DeepCopyInto(out *HostSingleton) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostSingleton.
+func (in *HostSingleton) DeepCopy() *HostSingleton {
+	if in == nil {
+		return nil
+	}
+	out := new(HostSingleton)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontend) DeepCopyInto(out *MicroFrontend) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontend.
+func (in *MicroFrontend) DeepCopy() *MicroFrontend {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MicroFrontend) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendHost) DeepCopyInto(out *MicroFrontendHost) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendHost.
+func (in *MicroFrontendHost) DeepCopy() *MicroFrontendHost {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MicroFrontendHost) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendHostList) DeepCopyInto(out *MicroFrontendHostList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MicroFrontendHost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendHostList.
+func (in *MicroFrontendHostList) DeepCopy() *MicroFrontendHostList {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendHostList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MicroFrontendHostList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendHostSpec) DeepCopyInto(out *MicroFrontendHostSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Remotes != nil {
+		in, out := &in.Remotes, &out.Remotes
+		*out = make([]HostRemote, len(*in))
+		copy(*out, *in)
+	}
+	if in.Singletons != nil {
+		in, out := &in.Singletons, &out.Singletons
+		*out = make([]HostSingleton, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendHostSpec.
+func (in *MicroFrontendHostSpec) DeepCopy() *MicroFrontendHostSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendHostSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendHostStatus) DeepCopyInto(out *MicroFrontendHostStatus) {
+	*out = *in
+	if in.Remotes != nil {
+		in, out := &in.Remotes, &out.Remotes
+		*out = make([]HostRemoteStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendHostStatus.
+func (in *MicroFrontendHostStatus) DeepCopy() *MicroFrontendHostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendHostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendList) DeepCopyInto(out *MicroFrontendList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MicroFrontend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendList.
+func (in *MicroFrontendList) DeepCopy() *MicroFrontendList {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MicroFrontendList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendSpec) DeepCopyInto(out *MicroFrontendSpec) {
+	*out = *in
+	if in.ExposedModules != nil {
+		in, out := &in.ExposedModules, &out.ExposedModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(UpdatePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SmokeCheck != nil {
+		in, out := &in.SmokeCheck, &out.SmokeCheck
+		*out = new(SmokeCheckSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendSpec.
+func (in *MicroFrontendSpec) DeepCopy() *MicroFrontendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicroFrontendStatus) DeepCopyInto(out *MicroFrontendStatus) {
+	*out = *in
+	if in.UpdatePolicy != nil {
+		in, out := &in.UpdatePolicy, &out.UpdatePolicy
+		*out = new(UpdatePolicyStatus)
+		**out = **in
+	}
+	if in.SharedModules != nil {
+		in, out := &in.SharedModules, &out.SharedModules
+		*out = make([]SharedModuleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Attestations != nil {
+		in, out := &in.Attestations, &out.Attestations
+		*out = new(AttestationSummary)
+		**out = **in
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SmokeCheck != nil {
+		in, out := &in.SmokeCheck, &out.SmokeCheck
+		*out = new(SmokeCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicroFrontendStatus.
+func (in *MicroFrontendStatus) DeepCopy() *MicroFrontendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MicroFrontendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Promotion) DeepCopyInto(out *Promotion) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Promotion.
+func (in *Promotion) DeepCopy() *Promotion {
+	if in == nil {
+		return nil
+	}
+	out := new(Promotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Promotion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionList) DeepCopyInto(out *PromotionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Promotion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionList.
+func (in *PromotionList) DeepCopy() *PromotionList {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PromotionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionSpec) DeepCopyInto(out *PromotionSpec) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]PromotionStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionSpec.
+func (in *PromotionSpec) DeepCopy() *PromotionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStage) DeepCopyInto(out *PromotionStage) {
+	*out = *in
+	if in.SmokeCheck != nil {
+		in, out := &in.SmokeCheck, &out.SmokeCheck
+		*out = new(SmokeCheckSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStage.
+func (in *PromotionStage) DeepCopy() *PromotionStage {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStageStatus) DeepCopyInto(out *PromotionStageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStageStatus.
+func (in *PromotionStageStatus) DeepCopy() *PromotionStageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionStatus) DeepCopyInto(out *PromotionStatus) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]PromotionStageStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionStatus.
+func (in *PromotionStatus) DeepCopy() *PromotionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RolloutStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	in.Stable.DeepCopyInto(&out.Stable)
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(RolloutVersion)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStep) DeepCopyInto(out *RolloutStep) {
+	*out = *in
+	if in.Pause != nil {
+		in, out := &in.Pause, &out.Pause
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStep.
+func (in *RolloutStep) DeepCopy() *RolloutStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutVersion) DeepCopyInto(out *RolloutVersion) {
+	*out = *in
+	if in.SharedModules != nil {
+		in, out := &in.SharedModules, &out.SharedModules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutVersion.
+func (in *RolloutVersion) DeepCopy() *RolloutVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleCollision) DeepCopyInto(out *SharedModuleCollision) {
+	*out = *in
+	out.SharedModuleReference = in.SharedModuleReference
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleCollision.
+func (in *SharedModuleCollision) DeepCopy() *SharedModuleCollision {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleCollision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleReference) DeepCopyInto(out *SharedModuleReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleReference.
+func (in *SharedModuleReference) DeepCopy() *SharedModuleReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleReport) DeepCopyInto(out *SharedModuleReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleReport.
+func (in *SharedModuleReport) DeepCopy() *SharedModuleReport {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedModuleReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleReportList) DeepCopyInto(out *SharedModuleReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedModuleReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleReportList.
+func (in *SharedModuleReportList) DeepCopy() *SharedModuleReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedModuleReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleReportStatus) DeepCopyInto(out *SharedModuleReportStatus) {
+	*out = *in
+	if in.SingletonConflicts != nil {
+		in, out := &in.SingletonConflicts, &out.SingletonConflicts
+		*out = make([]SharedModuleSkew, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fragmented != nil {
+		in, out := &in.Fragmented, &out.Fragmented
+		*out = make([]SharedModuleSkew, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleReportStatus.
+func (in *SharedModuleReportStatus) DeepCopy() *SharedModuleReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleSkew) DeepCopyInto(out *SharedModuleSkew) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]SharedModuleVersionUse, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleSkew.
+func (in *SharedModuleSkew) DeepCopy() *SharedModuleSkew {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleSkew)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleStatus) DeepCopyInto(out *SharedModuleStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleStatus.
+func (in *SharedModuleStatus) DeepCopy() *SharedModuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleVersion) DeepCopyInto(out *SharedModuleVersion) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleVersion.
+func (in *SharedModuleVersion) DeepCopy() *SharedModuleVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedModuleVersion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleVersionList) DeepCopyInto(out *SharedModuleVersionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedModuleVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleVersionList.
+func (in *SharedModuleVersionList) DeepCopy() *SharedModuleVersionList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleVersionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedModuleVersionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleVersionSpec) DeepCopyInto(out *SharedModuleVersionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleVersionSpec.
+func (in *SharedModuleVersionSpec) DeepCopy() *SharedModuleVersionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleVersionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleVersionStatus) DeepCopyInto(out *SharedModuleVersionStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.References != nil {
+		in, out := &in.References, &out.References
+		*out = make([]SharedModuleReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Collisions != nil {
+		in, out := &in.Collisions, &out.Collisions
+		*out = make([]SharedModuleCollision, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleVersionStatus.
+func (in *SharedModuleVersionStatus) DeepCopy() *SharedModuleVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedModuleVersionUse) DeepCopyInto(out *SharedModuleVersionUse) {
+	*out = *in
+	if in.MicroFrontends != nil {
+		in, out := &in.MicroFrontends, &out.MicroFrontends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedModuleVersionUse.
+func (in *SharedModuleVersionUse) DeepCopy() *SharedModuleVersionUse {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedModuleVersionUse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureTrust) DeepCopyInto(out *SignatureTrust) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RootCertificates != nil {
+		in, out := &in.RootCertificates, &out.RootCertificates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Identities != nil {
+		in, out := &in.Identities, &out.Identities
+		*out = make([]CertificateIdentity, len(*in))
+		copy(*out, *in)
+	}
+	if in.TransparencyLogKeys != nil {
+		in, out := &in.TransparencyLogKeys, &out.TransparencyLogKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureTrust.
+func (in *SignatureTrust) DeepCopy() *SignatureTrust {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureTrust)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmokeCheckFile) DeepCopyInto(out *SmokeCheckFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmokeCheckFile.
+func (in *SmokeCheckFile) DeepCopy() *SmokeCheckFile {
+	if in == nil {
+		return nil
+	}
+	out := new(SmokeCheckFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmokeCheckSpec) DeepCopyInto(out *SmokeCheckSpec) {
+	*out = *in
+	if in.Chunks != nil {
+		in, out := &in.Chunks, &out.Chunks
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmokeCheckSpec.
+func (in *SmokeCheckSpec) DeepCopy() *SmokeCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SmokeCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SmokeCheckStatus) DeepCopyInto(out *SmokeCheckStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]SmokeCheckFile, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmokeCheckStatus.
+func (in *SmokeCheckStatus) DeepCopy() *SmokeCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SmokeCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatePolicy) DeepCopyInto(out *UpdatePolicy) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatePolicy.
+func (in *UpdatePolicy) DeepCopy() *UpdatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdatePolicyStatus) DeepCopyInto(out *UpdatePolicyStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdatePolicyStatus.
+func (in *UpdatePolicyStatus) DeepCopy() *UpdatePolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdatePolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationPolicy) DeepCopyInto(out *VerificationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationPolicy.
+func (in *VerificationPolicy) DeepCopy() *VerificationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerificationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationPolicyList) DeepCopyInto(out *VerificationPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VerificationPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationPolicyList.
+func (in *VerificationPolicyList) DeepCopy() *VerificationPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VerificationPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationPolicySpec) DeepCopyInto(out *VerificationPolicySpec) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Cosign != nil {
+		in, out := &in.Cosign, &out.Cosign
+		*out = new(SignatureTrust)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notation != nil {
+		in, out := &in.Notation, &out.Notation
+		*out = new(SignatureTrust)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Attestations != nil {
+		in, out := &in.Attestations, &out.Attestations
+		*out = new(AttestationRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationPolicySpec.
+func (in *VerificationPolicySpec) DeepCopy() *VerificationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
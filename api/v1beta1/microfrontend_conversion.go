@@ -0,0 +1,6 @@
+// File: api/v1beta1/microfrontend_conversion.go
+package v1beta1
+
+// Hub marks v1beta1 as the version other MicroFrontend versions convert
+// through.
+func (*MicroFrontend) Hub() {}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/bundle/verify"
+)
+
+// checkAttestations records the SBOM and provenance summary of art in the
+// status and evaluates the attestation requirements of every applicable
+// VerificationPolicy as the Attested condition. It returns false when the
+// bundle must not be published.
+func (r *MicroFrontendReconciler) checkAttestations(ctx context.Context, mfe *v1alpha1.MicroFrontend, art *bundle.Artifact) (bool, error) {
+	att, err := bundle.DiscoverAttestations(ctx, art.Repository, art.Manifest)
+	if err != nil {
+		return false, fmt.Errorf("failed to discover attestations: %w", err)
+	}
+	mfe.Status.Attestations = &v1alpha1.AttestationSummary{
+		SBOMFormat:       att.SBOMFormat,
+		PackageCount:     att.PackageCount,
+		BuilderID:        att.BuilderID,
+		SourceRepository: att.SourceRepo,
+		SourceCommit:     att.SourceCommit,
+	}
+
+	var policies v1alpha1.VerificationPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return false, fmt.Errorf("failed to list verification policies: %w", err)
+	}
+
+	repository := art.Repository.Reference.Registry + "/" + art.Repository.Reference.Repository
+	var enforced []string
+	for i := range policies.Items {
+		p := &policies.Items[i]
+		if p.Spec.Attestations == nil || !policyApplies(p, repository) {
+			continue
+		}
+		var trust *verify.Trust
+		if p.Spec.Cosign != nil {
+			if trust, err = toVerifyTrust(p.Spec.Cosign); err != nil {
+				setAttested(mfe, metav1.ConditionFalse, "InvalidPolicy", fmt.Sprintf("VerificationPolicy %s: cosign: %v", p.Name, err))
+				return false, nil
+			}
+		}
+		if reason, msg := evaluateAttestations(p.Spec.Attestations, att, trust); reason != "" {
+			setAttested(mfe, metav1.ConditionFalse, reason, fmt.Sprintf("VerificationPolicy %s: %s", p.Name, msg))
+			return false, nil
+		}
+		enforced = append(enforced, p.Name)
+	}
+
+	if len(enforced) == 0 {
+		setAttested(mfe, metav1.ConditionTrue, "NoPolicy", "No VerificationPolicy requires attestations for "+repository)
+		return true, nil
+	}
+	setAttested(mfe, metav1.ConditionTrue, "AttestationsVerified", "Satisfies VerificationPolicy "+strings.Join(enforced, ", "))
+	return true, nil
+}
+
+// evaluateAttestations returns a condition reason and message describing why
+// att does not meet req, or an empty reason when it does. Every provenance
+// statement must be signed by a signer trust accepts and name an allowed
+// builder.
+func evaluateAttestations(req *v1alpha1.AttestationRequirements, att *bundle.Attestations, trust *verify.Trust) (string, string) {
+	if req.RequireSBOM && att.SBOMFormat == "" {
+		return "SBOMMissing", "no SPDX or CycloneDX SBOM is attached"
+	}
+	if len(req.AllowedBuilders) == 0 {
+		return "", ""
+	}
+	if len(att.Provenance) == 0 {
+		return "ProvenanceMissing", "no SLSA provenance is attached"
+	}
+	if trust == nil {
+		return "InvalidPolicy", "allowed builders require cosign trust to verify provenance signatures"
+	}
+	builders := make([]*regexp.Regexp, 0, len(req.AllowedBuilders))
+	for _, pattern := range req.AllowedBuilders {
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return "InvalidPolicy", fmt.Sprintf("invalid allowed builder %q: %v", pattern, err)
+		}
+		builders = append(builders, re)
+	}
+	for _, p := range att.Provenance {
+		if p.Envelope == nil {
+			return "ProvenanceUnverified", fmt.Sprintf("provenance from builder %q is not signed", p.BuilderID)
+		}
+		if _, err := verify.VerifyEnvelope(*p.Envelope, trust); err != nil {
+			return "ProvenanceUnverified", fmt.Sprintf("provenance from builder %q: %v", p.BuilderID, err)
+		}
+		if !matchesAny(builders, p.BuilderID) {
+			return "BuilderNotAllowed", fmt.Sprintf("builder %q is not allowed", p.BuilderID)
+		}
+	}
+	return "", ""
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func setAttested(mfe *v1alpha1.MicroFrontend, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mfe.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionAttested,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mfe.Generation,
+	})
+}
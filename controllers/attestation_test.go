@@ -0,0 +1,70 @@
+package controllers_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/bundle/verify"
+)
+
+func signedProvenance(t *testing.T, key *ecdsa.PrivateKey, builder string) bundle.Provenance {
+	payloadType, payload := "application/vnd.in-toto+json", `{"builder":"`+builder+`"}`
+	digest := sha256.Sum256([]byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	return bundle.Provenance{BuilderID: builder, Envelope: &verify.Envelope{
+		PayloadType: payloadType,
+		Payload:     []byte(payload),
+		Signatures:  [][]byte{sig},
+	}}
+}
+
+func TestEvaluateAttestations(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	trust := &verify.Trust{PublicKeys: []crypto.PublicKey{key.Public()}}
+	req := &v1alpha1.AttestationRequirements{AllowedBuilders: []string{`https://github\.com/actions/runner/.*`}}
+	github := "https://github.com/actions/runner/github-hosted"
+
+	tests := []struct {
+		name       string
+		provenance []bundle.Provenance
+		trust      *verify.Trust
+		reason     string
+	}{
+		{"signed by trusted key", []bundle.Provenance{signedProvenance(t, key, github)}, trust, ""},
+		{"no provenance", nil, trust, "ProvenanceMissing"},
+		{"no trust in policy", []bundle.Provenance{signedProvenance(t, key, github)}, nil, "InvalidPolicy"},
+		{"unsigned", []bundle.Provenance{{BuilderID: github}}, trust, "ProvenanceUnverified"},
+		{"signed by untrusted key", []bundle.Provenance{signedProvenance(t, other, github)}, trust, "ProvenanceUnverified"},
+		{"builder not allowed", []bundle.Provenance{signedProvenance(t, key, "https://ci.example.com")}, trust, "BuilderNotAllowed"},
+		{"pattern is anchored", []bundle.Provenance{signedProvenance(t, key, "https://evil.com/"+github)}, trust, "BuilderNotAllowed"},
+		{"every provenance must pass", []bundle.Provenance{
+			signedProvenance(t, key, github),
+			signedProvenance(t, key, "https://ci.example.com"),
+		}, trust, "BuilderNotAllowed"},
+		{"forged provenance next to a valid one", []bundle.Provenance{
+			signedProvenance(t, key, github),
+			signedProvenance(t, other, github),
+		}, trust, "ProvenanceUnverified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, msg := controllers.EvaluateAttestations(req, &bundle.Attestations{Provenance: tt.provenance}, tt.trust)
+			assert.Equal(t, tt.reason, reason, msg)
+		})
+	}
+}
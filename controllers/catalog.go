@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+	"mfe-operator/pkg/module"
+)
+
+// RemoteCatalogReconciler publishes the MicroFrontends matching
+// Selector as remotes.json and importmap.json, so that host applications
+// can discover remotes instead of hard-coding them. The files are written
+// to CDNTarget and to ConfigMap whenever their content changes; on the CDN,
+// catalog.json points at an immutable copy of both, see publish.
+type RemoteCatalogReconciler struct {
+	client.Client
+	CDN cdn.Backends
+
+	// Selector picks the MicroFrontends to list; nil lists all of them.
+	Selector labels.Selector
+	// CDNTarget is the "<backend>/<prefix>" the files are published under;
+	// empty skips publishing to the CDN.
+	CDNTarget string
+	// ConfigMap receives the files as data keys; an empty name skips it.
+	ConfigMap types.NamespacedName
+
+	// published is the hash of the catalog last seen on the CDN, saving
+	// the read of catalog.json while it is unchanged.
+	published string
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+
+func (r *RemoteCatalogReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var opts []client.ListOption
+	if r.Selector != nil {
+		opts = append(opts, client.MatchingLabelsSelector{Selector: r.Selector})
+	}
+	var list v1alpha1.MicroFrontendList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list MicroFrontends: %w", err)
+	}
+	remotes := catalogRemotes(list.Items, func(mfe, owner *v1alpha1.MicroFrontend, name string) {
+		logger.Info("Skipping MicroFrontend with duplicate remote name", "microfrontend", client.ObjectKeyFromObject(mfe),
+			"remote", name, "owner", client.ObjectKeyFromObject(owner))
+	})
+	remotesJSON, importMapJSON, err := module.RenderCatalog(remotes)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to render remote catalog: %w", err)
+	}
+
+	if r.CDNTarget != "" {
+		if err := r.publish(ctx, remotesJSON, importMapJSON); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if r.ConfigMap.Name != "" {
+		if err := r.updateConfigMap(ctx, remotesJSON, importMapJSON); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// publish uploads the catalog to CDNTarget unless catalog.json already
+// points at the same content. Both files are first written under
+// catalogs/<hash>/, which is never modified, then to their fixed names for
+// hosts that read only one of them, and finally catalog.json is switched
+// to the new pair. Older pairs are deleted afterwards; the previous one is
+// kept for hosts that have just read the old catalog.json.
+func (r *RemoteCatalogReconciler) publish(ctx context.Context, remotesJSON, importMapJSON []byte) error {
+	logger := log.FromContext(ctx)
+	sum := sha256.New()
+	sum.Write(remotesJSON)
+	sum.Write(importMapJSON)
+	hash := "sha256:" + hex.EncodeToString(sum.Sum(nil))
+	if hash == r.published {
+		return nil
+	}
+
+	backend, prefix, err := r.CDN.Resolve(r.CDNTarget)
+	if err != nil {
+		return err
+	}
+	current, err := fetchCatalogPointer(ctx, backend, prefix)
+	if err != nil {
+		// Publishing again is harmless; the files are rewritten as they are
+		logger.Info("Could not read the published catalog, publishing it again", "reason", err.Error())
+	}
+	if current.Hash == hash {
+		r.published = hash
+		return nil
+	}
+
+	dir := path.Join("catalogs", hash[len("sha256:"):len("sha256:")+12])
+	pointer := module.CatalogPointer{
+		Hash:      hash,
+		Remotes:   path.Join(dir, module.RemotesFile),
+		ImportMap: path.Join(dir, module.ImportMapFile),
+	}
+	pointerJSON, err := json.MarshalIndent(pointer, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", module.CatalogFile, err)
+	}
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{pointer.ImportMap, importMapJSON},
+		{pointer.Remotes, remotesJSON},
+		{module.ImportMapFile, importMapJSON},
+		{module.RemotesFile, remotesJSON},
+		{module.CatalogFile, pointerJSON},
+	} {
+		if err := cdn.UploadBytes(ctx, backend.Client, f.data, path.Join(prefix, f.name)); err != nil {
+			return fmt.Errorf("failed to publish %s: %w", f.name, err)
+		}
+	}
+	r.published = hash
+	logger.Info("Published remote catalog", "target", r.CDNTarget, "hash", hash)
+
+	if err := pruneCatalogs(ctx, backend, prefix, dir, path.Dir(current.Remotes)); err != nil {
+		logger.Error(err, "Failed to delete old remote catalogs")
+	}
+	return nil
+}
+
+// pruneCatalogs deletes the files under prefix/catalogs/ outside the
+// directories in keep. Backends that cannot list or delete files keep them.
+func pruneCatalogs(ctx context.Context, backend cdn.Backend, prefix string, keep ...string) error {
+	root := path.Join(prefix, "catalogs")
+	files, err := cdn.ListFiles(ctx, backend.Client, root)
+	if errors.Is(err, cdn.ErrListUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, dir := range keep {
+		kept[dir] = true
+	}
+	var old []string
+	for _, file := range files {
+		dir, _, _ := strings.Cut(file, "/")
+		if !kept[path.Join("catalogs", dir)] {
+			old = append(old, file)
+		}
+	}
+	if err := cdn.DeleteFiles(ctx, backend.Client, root, old); err != nil && !errors.Is(err, cdn.ErrDeleteUnsupported) {
+		return err
+	}
+	return nil
+}
+
+// fetchCatalogPointer reads catalog.json under prefix. It returns an empty
+// pointer if the backend cannot read files back.
+func fetchCatalogPointer(ctx context.Context, backend cdn.Backend, prefix string) (module.CatalogPointer, error) {
+	var pointer module.CatalogPointer
+	d, ok := backend.Client.(cdn.Downloader)
+	if !ok {
+		return pointer, nil
+	}
+	data, err := d.Download(ctx, path.Join(prefix, module.CatalogFile))
+	if err != nil {
+		return pointer, err
+	}
+	if err := json.Unmarshal(data, &pointer); err != nil {
+		return module.CatalogPointer{}, fmt.Errorf("failed to decode %s: %w", module.CatalogFile, err)
+	}
+	return pointer, nil
+}
+
+// updateConfigMap writes both files to the ConfigMap in a single update.
+func (r *RemoteCatalogReconciler) updateConfigMap(ctx context.Context, remotesJSON, importMapJSON []byte) error {
+	data := map[string]string{
+		module.RemotesFile:   string(remotesJSON),
+		module.ImportMapFile: string(importMapJSON),
+	}
+	var cm corev1.ConfigMap
+	err := r.Get(ctx, r.ConfigMap, &cm)
+	if apierrors.IsNotFound(err) {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: r.ConfigMap.Namespace, Name: r.ConfigMap.Name},
+			Data:       data,
+		}
+		if err := r.Create(ctx, &cm); err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s: %w", r.ConfigMap, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get ConfigMap %s: %w", r.ConfigMap, err)
+	}
+
+	if cm.Data[module.RemotesFile] == data[module.RemotesFile] && cm.Data[module.ImportMapFile] == data[module.ImportMapFile] {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	for k, v := range data {
+		cm.Data[k] = v
+	}
+	if err := r.Update(ctx, &cm); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s: %w", r.ConfigMap, err)
+	}
+	return nil
+}
+
+// catalogRemotes lists the MicroFrontends that have been published. A
+// MicroFrontend whose latest sync failed is still listed, since its last
+// published version is live. When two share a remote name, the one that
+// has existed longer keeps it, as with CDN prefixes, so that a new
+// MicroFrontend cannot take over a name hosts already load; skip is called
+// for the other.
+func catalogRemotes(mfes []v1alpha1.MicroFrontend, skip func(mfe, owner *v1alpha1.MicroFrontend, name string)) []module.Remote {
+	sort.Slice(mfes, func(i, j int) bool {
+		return claimedBefore(&mfes[i], &mfes[j])
+	})
+	owners := map[string]*v1alpha1.MicroFrontend{}
+	var remotes []module.Remote
+	for i := range mfes {
+		mfe := &mfes[i]
+		if mfe.Status.EntryURL == "" || !mfe.DeletionTimestamp.IsZero() {
+			continue
+		}
+		name := remoteName(mfe)
+		if owner, ok := owners[name]; ok {
+			skip(mfe, owner, name)
+			continue
+		}
+		owners[name] = mfe
+		remotes = append(remotes, module.Remote{
+			Name:    name,
+			URL:     mfe.Status.EntryURL,
+			Digest:  liveDigest(mfe),
+			Exposes: mfe.Spec.ExposedModules,
+		})
+	}
+	return remotes
+}
+
+// remoteName returns the name mfe is listed under in remote catalogs.
+func remoteName(mfe *v1alpha1.MicroFrontend) string {
+	if name := mfe.GetAnnotations()[v1alpha1.RemoteNameAnnotation]; name != "" {
+		return name
+	}
+	return mfe.Name
+}
+
+// SetupWithManager rebuilds the catalog whenever a MicroFrontend changes.
+func (r *RemoteCatalogReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.CDNTarget != "" {
+		if _, _, err := r.CDN.Resolve(r.CDNTarget); err != nil {
+			return err
+		}
+	}
+	catalog := func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "remote-catalog"}}}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("remotecatalog").
+		Watches(&source.Kind{Type: &v1alpha1.MicroFrontend{}}, handler.EnqueueRequestsFromMapFunc(catalog)).
+		Complete(r)
+}
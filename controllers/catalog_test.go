@@ -0,0 +1,113 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+	"mfe-operator/pkg/module"
+)
+
+func publishedMFE(namespace, name, entryURL string, lbls map[string]string) *v1alpha1.MicroFrontend {
+	return &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: lbls},
+		Spec:       v1alpha1.MicroFrontendSpec{ExposedModules: []string{"./App"}},
+		Status:     v1alpha1.MicroFrontendStatus{Synced: true, Digest: "sha256:" + name, EntryURL: entryURL},
+	}
+}
+
+func TestRemoteCatalog(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	shell := map[string]string{"host": "shell"}
+	renamed := publishedMFE("shop", "checkout-v2", "https://cdn.example.com/apps/checkout/remoteEntry.js", shell)
+	renamed.Annotations = map[string]string{v1alpha1.RemoteNameAnnotation: "checkout"}
+	renamed.CreationTimestamp = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	// Loses the name to the older shop/checkout-v2
+	newer := publishedMFE("aaa", "checkout", "https://cdn.example.com/apps/impostor/remoteEntry.js", shell)
+	newer.CreationTimestamp = metav1.NewTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		renamed,
+		newer,
+		publishedMFE("shop", "catalog", "https://cdn.example.com/apps/catalog/remoteEntry.js", shell),
+		publishedMFE("shop", "unpublished", "", shell),
+		publishedMFE("shop", "admin", "https://cdn.example.com/apps/admin/remoteEntry.js", nil),
+	).Build()
+	files := memoryCDN{}
+	r := &controllers.RemoteCatalogReconciler{
+		Client:    k8s,
+		CDN:       cdn.Backends{"primary": {Client: files}},
+		Selector:  labels.SelectorFromSet(shell),
+		CDNTarget: "primary/catalog",
+		ConfigMap: types.NamespacedName{Namespace: "mfe-system", Name: "remotes"},
+	}
+	_, err := r.Reconcile(context.Background(), ctrl.Request{})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"remotes": [
+		{"name": "catalog", "url": "https://cdn.example.com/apps/catalog/remoteEntry.js", "digest": "sha256:catalog", "exposes": ["./App"]},
+		{"name": "checkout", "url": "https://cdn.example.com/apps/checkout/remoteEntry.js", "digest": "sha256:checkout-v2", "exposes": ["./App"]}
+	]}`, string(files["catalog/remotes.json"]))
+	assert.JSONEq(t, `{"imports": {
+		"catalog": "https://cdn.example.com/apps/catalog/remoteEntry.js",
+		"checkout": "https://cdn.example.com/apps/checkout/remoteEntry.js"
+	}}`, string(files["catalog/importmap.json"]))
+
+	var cm corev1.ConfigMap
+	require.NoError(t, k8s.Get(context.Background(), r.ConfigMap, &cm))
+	assert.Equal(t, string(files["catalog/remotes.json"]), cm.Data["remotes.json"])
+	assert.Equal(t, string(files["catalog/importmap.json"]), cm.Data["importmap.json"])
+
+	// catalog.json points at an immutable copy of both files
+	var pointer module.CatalogPointer
+	require.NoError(t, json.Unmarshal(files["catalog/catalog.json"], &pointer))
+	assert.Equal(t, files["catalog/remotes.json"], files["catalog/"+pointer.Remotes])
+	assert.Equal(t, files["catalog/importmap.json"], files["catalog/"+pointer.ImportMap])
+
+	// Unchanged catalogs are not uploaded again, also after a restart
+	delete(files, "catalog/remotes.json")
+	_, err = r.Reconcile(context.Background(), ctrl.Request{})
+	require.NoError(t, err)
+	assert.NotContains(t, files, "catalog/remotes.json")
+	restarted := &controllers.RemoteCatalogReconciler{Client: k8s, CDN: r.CDN, Selector: r.Selector, CDNTarget: r.CDNTarget}
+	_, err = restarted.Reconcile(context.Background(), ctrl.Request{})
+	require.NoError(t, err)
+	assert.NotContains(t, files, "catalog/remotes.json")
+
+	// A change publishes a new pair; the previous one is kept, older ones go
+	for _, name := range []string{"search", "cart"} {
+		require.NoError(t, k8s.Create(context.Background(), publishedMFE("shop", name, "https://cdn.example.com/apps/"+name+"/remoteEntry.js", shell)))
+		_, err = r.Reconcile(context.Background(), ctrl.Request{})
+		require.NoError(t, err)
+	}
+	var latest module.CatalogPointer
+	require.NoError(t, json.Unmarshal(files["catalog/catalog.json"], &latest))
+	assert.Contains(t, string(files["catalog/"+latest.Remotes]), "cart")
+	assert.Equal(t, files["catalog/remotes.json"], files["catalog/"+latest.Remotes])
+	assert.NotContains(t, files, "catalog/"+pointer.Remotes)
+	var catalogs int
+	for name := range files {
+		if strings.HasPrefix(name, "catalog/catalogs/") {
+			catalogs++
+		}
+	}
+	assert.Equal(t, 4, catalogs)
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/module"
+)
+
+// permanentError marks an error that retrying cannot fix; only a change to
+// the MicroFrontend or the artifact it references can.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marks err as permanent.
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// isPermanent classifies a pipeline error. Bad references, missing
+// artifacts, invalid bundles and shared modules, and registry 4xx responses
+// are permanent; everything else is transient, including registry 5xx,
+// throttling, and authentication failures, which clear once credentials are
+// fixed or refreshed.
+func isPermanent(err error) bool {
+	var p *permanentError
+	if errors.As(err, &p) {
+		return true
+	}
+	if errors.Is(err, bundle.ErrInvalidBundle) ||
+		errors.Is(err, module.ErrInvalidSharedModule) ||
+		errors.Is(err, bundle.ErrNoMatchingTag) ||
+		errors.Is(err, errdef.ErrInvalidReference) ||
+		errors.Is(err, errdef.ErrNotFound) {
+		return true
+	}
+	var resp *errcode.ErrorResponse
+	if errors.As(err, &resp) {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return false
+		}
+		return resp.StatusCode >= 400 && resp.StatusCode < 500
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package controllers_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/module"
+)
+
+func registryError(status int) error {
+	return fmt.Errorf("failed to resolve: %w", &errcode.ErrorResponse{Method: http.MethodGet, StatusCode: status})
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{"invalid bundle", fmt.Errorf("%w: no layers", bundle.ErrInvalidBundle), true},
+		{"invalid shared module", fmt.Errorf("%w: bad name", module.ErrInvalidSharedModule), true},
+		{"no matching tag", bundle.ErrNoMatchingTag, true},
+		{"invalid reference", errdef.ErrInvalidReference, true},
+		{"not found", errdef.ErrNotFound, true},
+		{"bad request", registryError(http.StatusBadRequest), true},
+		{"registry not found", registryError(http.StatusNotFound), true},
+		{"unauthorized", registryError(http.StatusUnauthorized), false},
+		{"forbidden", registryError(http.StatusForbidden), false},
+		{"request timeout", registryError(http.StatusRequestTimeout), false},
+		{"throttled", registryError(http.StatusTooManyRequests), false},
+		{"server error", registryError(http.StatusBadGateway), false},
+		{"network", errors.New("connection reset by peer"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.permanent, controllers.IsPermanent(tt.err))
+		})
+	}
+}
+
+func TestRequeueAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		resync time.Duration
+		spec   v1alpha1.MicroFrontendSpec
+		want   time.Duration
+	}{
+		{"default", 0, v1alpha1.MicroFrontendSpec{}, 10 * time.Minute},
+		{"operator resync", time.Hour, v1alpha1.MicroFrontendSpec{}, time.Hour},
+		{"sync interval", time.Hour, v1alpha1.MicroFrontendSpec{SyncInterval: &metav1.Duration{Duration: 2 * time.Minute}}, 2 * time.Minute},
+		{"update policy default", time.Hour, v1alpha1.MicroFrontendSpec{UpdatePolicy: &v1alpha1.UpdatePolicy{}}, 5 * time.Minute},
+		{"update policy interval", 0, v1alpha1.MicroFrontendSpec{UpdatePolicy: &v1alpha1.UpdatePolicy{Interval: &metav1.Duration{Duration: time.Minute}}}, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &controllers.MicroFrontendReconciler{ResyncPeriod: tt.resync}
+			assert.Equal(t, tt.want, controllers.RequeueAfter(r, &v1alpha1.MicroFrontend{Spec: tt.spec}))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package controllers
+
+// PublishSharedModules exposes publishSharedModules to the external tests.
+var PublishSharedModules = (*MicroFrontendReconciler).publishSharedModules
+
+// IsPermanent and RequeueAfter expose the error classification and requeue
+// interval to the external tests.
+var (
+	IsPermanent  = isPermanent
+	RequeueAfter = (*MicroFrontendReconciler).requeueAfter
+)
+
+// RecordPromotion exposes recordPromotion to the external tests.
+var RecordPromotion = (*MicroFrontendReconciler).recordPromotion
+
+// StartRollout, ProgressRollout and RetireVersions expose the rollout steps
+// to the external tests.
+var (
+	StartRollout    = startRollout
+	ProgressRollout = (*MicroFrontendReconciler).progressRollout
+	RetireVersions  = retireVersions
+)
+
+// PlanSmokeCheck and SmokeCheck expose the smoke check stage to the
+// external tests.
+var (
+	PlanSmokeCheck = planSmokeCheck
+	SmokeCheck     = (*MicroFrontendReconciler).smokeCheck
+)
+
+// CheckExposes exposes checkExposes to the external tests.
+var CheckExposes = (*MicroFrontendReconciler).checkExposes
+
+// EvaluateAttestations exposes the attestation policy check to the external
+// tests.
+var EvaluateAttestations = evaluateAttestations
+
+// PolicyApplies exposes the policy repository matching to the external
+// tests.
+var PolicyApplies = policyApplies
+
+// ToVerifyTrust exposes the policy trust parsing to the external tests.
+var ToVerifyTrust = toVerifyTrust
+
+// FetchBundle exposes the bundle fetch step of Reconcile to the external
+// tests.
+var FetchBundle = (*MicroFrontendReconciler).fetchBundle
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/module"
+)
+
+// checkExposes compares Spec.ExposedModules with the modules the bundle
+// actually exposes and records the outcome as the ModulesExposed condition.
+// It returns false when a declared module is missing, since hosts importing
+// it would break at runtime. Undeclared exposes only raise a warning event,
+// and only when the spec declares any exposes at all.
+func (r *MicroFrontendReconciler) checkExposes(ctx context.Context, mfe *v1alpha1.MicroFrontend, bundlePath, entry string) (bool, error) {
+	exposed, err := module.DiscoverExposes(bundlePath, entry)
+	if err != nil {
+		return false, fmt.Errorf("failed to discover exposed modules: %w", err)
+	}
+	missing, extra := module.CompareExposes(mfe.Spec.ExposedModules, exposed)
+
+	if len(extra) > 0 && len(mfe.Spec.ExposedModules) > 0 {
+		r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "UndeclaredExposes",
+			"Bundle exposes modules not listed in spec.exposedModules: %s", strings.Join(extra, ", "))
+	}
+	if len(missing) > 0 {
+		found := "none"
+		if len(exposed) > 0 {
+			found = strings.Join(exposed, ", ")
+		}
+		setModulesExposed(mfe, metav1.ConditionFalse, "MissingExposes",
+			fmt.Sprintf("Bundle does not expose %s declared in spec.exposedModules; it exposes: %s", strings.Join(missing, ", "), found))
+		return false, nil
+	}
+
+	message := fmt.Sprintf("All %d declared modules are exposed", len(mfe.Spec.ExposedModules))
+	if len(extra) > 0 {
+		message += "; also exposes undeclared " + strings.Join(extra, ", ")
+	}
+	setModulesExposed(mfe, metav1.ConditionTrue, "AllExposed", message)
+	return true, nil
+}
+
+func setModulesExposed(mfe *v1alpha1.MicroFrontend, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mfe.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionModulesExposed,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mfe.Generation,
+	})
+}
@@ -0,0 +1,37 @@
+package controllers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+)
+
+func TestCheckExposesWarnsOnlyWhenDeclared(t *testing.T) {
+	ctx := context.Background()
+	bundle := writeSharedBundle(t, map[string]string{
+		"remoteEntry.js":   "",
+		"mf-manifest.json": `{"exposes": [{"name": "Cart", "path": "./Cart"}, {"name": "MiniCart", "path": "./widgets/MiniCart"}]}`,
+	})
+	recorder := record.NewFakeRecorder(10)
+	r := &controllers.MicroFrontendReconciler{Recorder: recorder}
+	mfe := &v1alpha1.MicroFrontend{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}}
+
+	// Nothing declared: every expose is accepted silently
+	ok, err := controllers.CheckExposes(r, ctx, mfe, bundle, "remoteEntry.js")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, recorder.Events)
+
+	mfe.Spec.ExposedModules = []string{"./Cart"}
+	ok, err = controllers.CheckExposes(r, ctx, mfe, bundle, "remoteEntry.js")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, <-recorder.Events, "Warning UndeclaredExposes Bundle exposes modules not listed in spec.exposedModules: ./widgets/MiniCart")
+}
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"oras.land/oras-go/v2/registry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+	"mfe-operator/pkg/module"
+)
+
+// MicroFrontendHostReconciler resolves the remotes of each MicroFrontendHost
+// and publishes its host manifest.
+type MicroFrontendHostReconciler struct {
+	client.Client
+	CDN cdn.Backends
+}
+
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=microfrontendhosts,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=microfrontendhosts/status,verbs=get;update;patch
+
+func (r *MicroFrontendHostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var host v1alpha1.MicroFrontendHost
+	if err := r.Get(ctx, req.NamespacedName, &host); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	before := host.Status.DeepCopy()
+	host.Status.ObservedGeneration = host.Generation
+
+	manifest, err := r.resolveRemotes(ctx, &host)
+	if err != nil {
+		if isPermanent(err) {
+			setHostReady(&host, metav1.ConditionFalse, "InvalidSpec", err.Error())
+			return ctrl.Result{}, r.updateHostStatus(ctx, &host, before)
+		}
+		return ctrl.Result{}, err
+	}
+	backend, prefix, err := r.CDN.Resolve(host.Spec.CDNTarget)
+	if err != nil {
+		setHostReady(&host, metav1.ConditionFalse, "InvalidSpec", err.Error())
+		return ctrl.Result{}, r.updateHostStatus(ctx, &host, before)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to encode host manifest: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := "sha256:" + hex.EncodeToString(sum[:])
+	manifestURL := backend.URL(path.Join(prefix, module.HostManifestFile))
+	if hash != host.Status.ManifestHash || manifestURL != host.Status.ManifestURL {
+		if err := cdn.UploadBytes(ctx, backend.Client, data, path.Join(prefix, module.HostManifestFile)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to publish host manifest: %w", err)
+		}
+		host.Status.ManifestHash = hash
+		host.Status.ManifestURL = manifestURL
+		host.Status.PublishedAt = time.Now().Format(time.RFC3339)
+		logger.Info("Published host manifest", "url", manifestURL, "remotes", len(manifest.Remotes))
+	}
+
+	var missing, incompatible int
+	for _, st := range host.Status.Remotes {
+		switch st.State {
+		case v1alpha1.RemoteMissing:
+			missing++
+		case v1alpha1.RemoteIncompatible:
+			incompatible++
+		}
+	}
+	if missing+incompatible == 0 {
+		setHostReady(&host, metav1.ConditionTrue, "RemotesResolved", fmt.Sprintf("All %d remotes resolved", len(host.Status.Remotes)))
+	} else {
+		setHostReady(&host, metav1.ConditionFalse, "RemotesUnresolved", fmt.Sprintf("%d missing, %d incompatible remotes", missing, incompatible))
+	}
+	return ctrl.Result{}, r.updateHostStatus(ctx, &host, before)
+}
+
+// resolveRemotes selects the host's MicroFrontends, records how each was
+// resolved in host.Status.Remotes and returns the manifest of the resolved
+// ones.
+func (r *MicroFrontendHostReconciler) resolveRemotes(ctx context.Context, host *v1alpha1.MicroFrontendHost) (*module.HostManifest, error) {
+	selector := labels.Nothing()
+	if host.Spec.Selector != nil {
+		s, err := metav1.LabelSelectorAsSelector(host.Spec.Selector)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("invalid selector: %w", err))
+		}
+		selector = s
+	}
+
+	var list v1alpha1.MicroFrontendList
+	if err := r.List(ctx, &list, client.InNamespace(host.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list MicroFrontends: %w", err)
+	}
+	mfes := map[string]*v1alpha1.MicroFrontend{}
+	constraints := map[string]string{}
+	for i := range list.Items {
+		mfe := &list.Items[i]
+		mfes[mfe.Name] = mfe
+		if selector.Matches(labels.Set(mfe.Labels)) {
+			constraints[mfe.Name] = ""
+		}
+	}
+	for _, remote := range host.Spec.Remotes {
+		constraints[remote.MicroFrontend] = remote.Version
+	}
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest := &module.HostManifest{Remotes: map[string]module.HostManifestRemote{}}
+	for _, s := range host.Spec.Singletons {
+		if manifest.Shared == nil {
+			manifest.Shared = map[string]module.HostManifestShared{}
+		}
+		manifest.Shared[s.Name] = module.HostManifestShared{Version: s.Version, Singleton: true}
+	}
+
+	host.Status.Remotes = nil
+	for _, name := range names {
+		st := v1alpha1.HostRemoteStatus{Name: name, MicroFrontend: name, State: v1alpha1.RemoteMissing}
+		mfe, ok := mfes[name]
+		switch {
+		case !ok:
+			st.Message = "MicroFrontend not found"
+		case mfe.Status.EntryURL == "":
+			st.Message = "MicroFrontend has not been published"
+		default:
+			st.Name = remoteName(mfe)
+			st.State = v1alpha1.RemoteIncompatible
+			versions, msg := checkRemoteVersion(constraints[name], servedVersions(mfe))
+			if len(versions) > 0 {
+				st.URL, st.Digest, st.Version = versions[0].URL, versions[0].Digest, versions[0].Tag
+			}
+			if msg != "" {
+				st.Message = msg
+			} else if msg := checkSingletons(host.Spec.Singletons, mfe); msg != "" {
+				st.Message = msg
+			} else if _, taken := manifest.Remotes[st.Name]; taken {
+				st.Message = fmt.Sprintf("remote name %q is used by another MicroFrontend", st.Name)
+			} else {
+				st.State = v1alpha1.RemoteResolved
+				remote := module.HostManifestRemote{
+					URL:     st.URL,
+					Version: st.Version,
+					Digest:  st.Digest,
+					Exposes: mfe.Spec.ExposedModules,
+				}
+				if len(versions) > 1 {
+					for _, v := range versions {
+						remote.Versions = append(remote.Versions, module.WeightedRemote{URL: v.URL, Digest: v.Digest, Weight: v.Weight})
+					}
+				}
+				manifest.Remotes[st.Name] = remote
+			}
+		}
+		host.Status.Remotes = append(host.Status.Remotes, st)
+	}
+	return manifest, nil
+}
+
+// publishedTag returns the tag of the bundle mfe last published, or "" if
+// it was referenced by digest.
+func publishedTag(mfe *v1alpha1.MicroFrontend) string {
+	return versionTag(mfe, mfe.Status.Digest)
+}
+
+// versionTag returns the tag the bundle with digest was deployed from under
+// mfe's current spec, or "" if it was referenced by digest.
+func versionTag(mfe *v1alpha1.MicroFrontend, digest string) string {
+	if up := mfe.Status.UpdatePolicy; up != nil && up.Tag != "" && up.Digest == digest {
+		return up.Tag
+	}
+	ref, err := registry.ParseReference(mfe.Spec.OCIArtifact)
+	if err != nil {
+		return ""
+	}
+	if _, err := ref.Digest(); err == nil {
+		return ""
+	}
+	return ref.Reference
+}
+
+// remoteVersion is a version of a MicroFrontend served to Weight percent
+// of users.
+type remoteVersion struct {
+	URL    string
+	Digest string
+	Tag    string
+	Weight int32
+}
+
+// servedVersions returns the versions of mfe that receive traffic, the
+// stable one first while a rollout is in progress.
+func servedVersions(mfe *v1alpha1.MicroFrontend) []remoteVersion {
+	st := mfe.Status.Rollout
+	if st == nil {
+		return []remoteVersion{{URL: mfe.Status.EntryURL, Digest: mfe.Status.Digest, Tag: publishedTag(mfe), Weight: 100}}
+	}
+	candidates := []remoteVersion{{URL: st.Stable.EntryURL, Digest: st.Stable.Digest, Tag: st.Stable.Tag, Weight: 100 - st.Weight}}
+	if st.Canary != nil {
+		candidates = append(candidates, remoteVersion{URL: st.Canary.EntryURL, Digest: st.Canary.Digest, Tag: st.Canary.Tag, Weight: st.Weight})
+	}
+	var versions []remoteVersion
+	for _, v := range candidates {
+		if v.Weight > 0 && v.URL != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// checkRemoteVersion returns the versions that satisfy a host's version pin
+// or range, or describes why none does. A digest pin selects at most one.
+func checkRemoteVersion(constraint string, versions []remoteVersion) ([]remoteVersion, string) {
+	if constraint == "" {
+		return versions, ""
+	}
+	var matched []remoteVersion
+	var served []string
+	if strings.HasPrefix(constraint, "sha256:") {
+		for _, v := range versions {
+			if v.Digest == constraint {
+				matched = append(matched, v)
+			}
+			served = append(served, v.Digest)
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Sprintf("published digest %s is not the pinned %s", strings.Join(served, ", "), constraint)
+		}
+		return matched[:1], ""
+	}
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid version constraint %q: %v", constraint, err)
+	}
+	for _, v := range versions {
+		served = append(served, v.Tag)
+		sv, err := semver.NewVersion(v.Tag)
+		if err != nil {
+			continue
+		}
+		if c.Check(sv) {
+			matched = append(matched, v)
+		}
+	}
+	if len(matched) > 0 {
+		return matched, ""
+	}
+	if len(versions) == 1 {
+		if _, err := semver.NewVersion(versions[0].Tag); err != nil {
+			return nil, fmt.Sprintf("published tag %q is not a semantic version", versions[0].Tag)
+		}
+	}
+	return nil, fmt.Sprintf("published version %s does not satisfy %s", strings.Join(served, ", "), constraint)
+}
+
+// checkSingletons describes the first shared module of mfe that does not
+// accept the version the host provides.
+func checkSingletons(singletons []v1alpha1.HostSingleton, mfe *v1alpha1.MicroFrontend) string {
+	for _, s := range singletons {
+		for _, m := range mfe.Status.SharedModules {
+			shared := module.SharedModule{Name: m.Name, Version: m.Version, RequiredVersion: m.RequiredVersion}
+			if m.Name == s.Name && !module.AcceptsVersion(shared, s.Version) {
+				want := m.RequiredVersion
+				if want == "" {
+					want = m.Version
+				}
+				return fmt.Sprintf("shares %s %s but the host provides %s", m.Name, want, s.Version)
+			}
+		}
+	}
+	return ""
+}
+
+func setHostReady(host *v1alpha1.MicroFrontendHost, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&host.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: host.Generation,
+	})
+}
+
+func (r *MicroFrontendHostReconciler) updateHostStatus(ctx context.Context, host *v1alpha1.MicroFrontendHost, before *v1alpha1.MicroFrontendHostStatus) error {
+	if equality.Semantic.DeepEqual(&host.Status, before) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, host); err != nil {
+		return fmt.Errorf("failed to update MicroFrontendHost status: %w", err)
+	}
+	return nil
+}
+
+// SetupWithManager also re-resolves the hosts in a MicroFrontend's namespace
+// whenever it changes.
+func (r *MicroFrontendHostReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	hosts := func(obj client.Object) []reconcile.Request {
+		var list v1alpha1.MicroFrontendHostList
+		if err := r.List(context.Background(), &list, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(list.Items))
+		for _, host := range list.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&host)})
+		}
+		return requests
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.MicroFrontendHost{}).
+		Watches(&source.Kind{Type: &v1alpha1.MicroFrontend{}}, handler.EnqueueRequestsFromMapFunc(hosts)).
+		Complete(r)
+}
@@ -0,0 +1,136 @@
+package controllers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+func hostRemote(name, tag string, lbls map[string]string, shared ...v1alpha1.SharedModuleStatus) *v1alpha1.MicroFrontend {
+	return &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: lbls},
+		Spec:       v1alpha1.MicroFrontendSpec{OCIArtifact: "registry.example.com/mfe/" + name + ":" + tag},
+		Status: v1alpha1.MicroFrontendStatus{
+			Digest:        "sha256:" + name,
+			EntryURL:      "https://cdn.example.com/apps/" + name + "/remoteEntry.js",
+			SharedModules: shared,
+		},
+	}
+}
+
+func TestMicroFrontendHost(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	shell := map[string]string{"host": "shell"}
+	react18 := v1alpha1.SharedModuleStatus{Name: "react", Version: "18.2.0", RequiredVersion: "^18.0.0", Singleton: true}
+	react17 := v1alpha1.SharedModuleStatus{Name: "react", Version: "17.0.2", RequiredVersion: "^17.0.0", Singleton: true}
+	host := &v1alpha1.MicroFrontendHost{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "shell", Generation: 1},
+		Spec: v1alpha1.MicroFrontendHostSpec{
+			CDNTarget: "primary/hosts/shell",
+			Selector:  &metav1.LabelSelector{MatchLabels: shell},
+			Remotes: []v1alpha1.HostRemote{
+				{MicroFrontend: "checkout", Version: "^1.4.0"},
+				{MicroFrontend: "search"},
+			},
+			Singletons: []v1alpha1.HostSingleton{{Name: "react", Version: "18.3.1"}},
+		},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		host,
+		hostRemote("checkout", "v1.4.2", nil, react18),
+		hostRemote("catalog", "v2.0.0", shell, react17),
+		hostRemote("cart", "v3.1.0", shell),
+		hostRemote("admin", "v1.0.0", nil),
+	).Build()
+	files := memoryCDN{}
+	r := &controllers.MicroFrontendHostReconciler{Client: k8s, CDN: cdn.Backends{"primary": {Client: files, PublicURL: "https://cdn.example.com"}}}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+	require.NoError(t, err)
+	require.NoError(t, k8s.Get(context.Background(), client.ObjectKeyFromObject(host), host))
+
+	states := map[string]string{}
+	for _, st := range host.Status.Remotes {
+		states[st.MicroFrontend] = st.State
+	}
+	assert.Equal(t, map[string]string{
+		"cart":     v1alpha1.RemoteResolved,
+		"catalog":  v1alpha1.RemoteIncompatible,
+		"checkout": v1alpha1.RemoteResolved,
+		"search":   v1alpha1.RemoteMissing,
+	}, states)
+	assert.Equal(t, "https://cdn.example.com/hosts/shell/host-manifest.json", host.Status.ManifestURL)
+	assert.True(t, meta.IsStatusConditionFalse(host.Status.Conditions, v1alpha1.ConditionReady))
+
+	assert.JSONEq(t, `{
+		"remotes": {
+			"cart": {"url": "https://cdn.example.com/apps/cart/remoteEntry.js", "version": "v3.1.0", "digest": "sha256:cart"},
+			"checkout": {"url": "https://cdn.example.com/apps/checkout/remoteEntry.js", "version": "v1.4.2", "digest": "sha256:checkout"}
+		},
+		"shared": {"react": {"version": "18.3.1", "singleton": true}}
+	}`, string(files["hosts/shell/host-manifest.json"]))
+
+	// A version outside the range makes the remote incompatible
+	host.Spec.Remotes[0].Version = "^2.0.0"
+	require.NoError(t, k8s.Update(context.Background(), host))
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+	require.NoError(t, err)
+	require.NoError(t, k8s.Get(context.Background(), client.ObjectKeyFromObject(host), host))
+	assert.Equal(t, "published version v1.4.2 does not satisfy ^2.0.0", host.Status.Remotes[2].Message)
+	assert.NotContains(t, string(files["hosts/shell/host-manifest.json"]), "checkout")
+}
+
+func TestMicroFrontendHostDuringRollout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cart := hostRemote("cart", "v3.2.0", nil)
+	stable := v1alpha1.RolloutVersion{Digest: "sha256:stable", EntryURL: "https://cdn.example.com/apps/cart/versions/stable/remoteEntry.js", Tag: "v3.1.0"}
+	canary := v1alpha1.RolloutVersion{Digest: "sha256:canary", EntryURL: "https://cdn.example.com/apps/cart/versions/canary/remoteEntry.js", Tag: "v3.2.0"}
+	cart.Status.Digest, cart.Status.EntryURL = canary.Digest, stable.EntryURL
+	cart.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutProgressing, Stable: stable, Canary: &canary, Weight: 10}
+
+	tests := []struct {
+		version  string
+		manifest string
+	}{
+		{"", `{"url": "` + stable.EntryURL + `", "version": "v3.1.0", "digest": "sha256:stable", "versions": [
+			{"url": "` + stable.EntryURL + `", "digest": "sha256:stable", "weight": 90},
+			{"url": "` + canary.EntryURL + `", "digest": "sha256:canary", "weight": 10}]}`},
+		{"sha256:stable", `{"url": "` + stable.EntryURL + `", "version": "v3.1.0", "digest": "sha256:stable"}`},
+		{"sha256:canary", `{"url": "` + canary.EntryURL + `", "version": "v3.2.0", "digest": "sha256:canary"}`},
+		{"~3.1.0", `{"url": "` + stable.EntryURL + `", "version": "v3.1.0", "digest": "sha256:stable"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			host := &v1alpha1.MicroFrontendHost{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "shell", Generation: 1},
+				Spec: v1alpha1.MicroFrontendHostSpec{
+					CDNTarget: "primary/hosts/shell",
+					Remotes:   []v1alpha1.HostRemote{{MicroFrontend: "cart", Version: tt.version}},
+				},
+			}
+			k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(host, cart.DeepCopy()).Build()
+			files := memoryCDN{}
+			r := &controllers.MicroFrontendHostReconciler{Client: k8s, CDN: cdn.Backends{"primary": {Client: files, PublicURL: "https://cdn.example.com"}}}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+			require.NoError(t, err)
+			require.NoError(t, k8s.Get(context.Background(), client.ObjectKeyFromObject(host), host))
+			assert.Equal(t, v1alpha1.RemoteResolved, host.Status.Remotes[0].State, host.Status.Remotes[0].Message)
+			assert.JSONEq(t, `{"remotes": {"cart": `+tt.manifest+`}}`, string(files["hosts/shell/host-manifest.json"]))
+		})
+	}
+}
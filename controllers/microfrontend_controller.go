@@ -3,12 +3,20 @@ package controllers
 import (
 	context "context"
 	"fmt"
+	"net/http"
+	"path"
 	"time"
 
 	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/bundle/cdn"
+	"mfe-operator/pkg/module"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -17,12 +25,37 @@ import (
 // MicroFrontendReconciler reconciles a MicroFrontend object
 type MicroFrontendReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Workspaces owns the per-reconcile scratch directories.
+	Workspaces *bundle.WorkspaceManager
+	// Cache holds extracted bundles across reconciles, keyed by layer digest.
+	// When nil, every reconcile fetches and extracts into its workspace.
+	Cache *bundle.BlobCache
+	// CDN maps backend names to clients; Spec.CDNTarget names one of them.
+	CDN cdn.Backends
+	// CatalogTarget is where the remote catalog is published, if anywhere;
+	// MicroFrontends may not publish over it.
+	CatalogTarget string
+	// DriftCheck makes unchanged reconciles also confirm that the CDN still
+	// serves the recorded digest before skipping the publish.
+	DriftCheck bool
+	// ResyncPeriod is the delay between periodic reconciles of a synced
+	// MicroFrontend. Defaults to 10 minutes.
+	ResyncPeriod time.Duration
+	// HTTPClient fetches published files for smoke checks. Defaults to a
+	// client with a 10 second timeout.
+	HTTPClient *http.Client
 }
 
+// defaultResyncPeriod is used when ResyncPeriod is not set.
+const defaultResyncPeriod = 10 * time.Minute
+
 //+kubebuilder:rbac:groups=platform.mycorp.com,resources=microfrontends,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=platform.mycorp.com,resources=microfrontends/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=platform.mycorp.com,resources=microfrontends/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *MicroFrontendReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -38,22 +71,389 @@ func (r *MicroFrontendReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	// Simulated: Process the OCI artifact, extract, and upload to CDN
 	logger.Info("Processing MicroFrontend", "name", mfe.Name, "oci", mfe.Spec.OCIArtifact)
 
+	// Pick the reference to deploy, polling the registry if an update policy is set
+	var lastChecked string
+	if mfe.Status.UpdatePolicy != nil {
+		lastChecked = mfe.Status.UpdatePolicy.LastCheckedAt
+	}
+	ref, err := r.resolveUpdatePolicy(ctx, &mfe)
+	if err != nil {
+		logger.Error(err, "Failed to apply update policy")
+		return r.fail(ctx, &mfe, err)
+	}
+
+	// Resolve the manifest digest; if it was already published for this spec there is nothing to do
+	repo, manifestDesc, err := bundle.ResolveManifest(ctx, ref)
+	if err != nil {
+		logger.Error(err, "Failed to resolve OCI artifact")
+		return r.fail(ctx, &mfe, err)
+	}
+	if r.upToDate(ctx, &mfe, manifestDesc.Digest.String()) {
+		logger.Info("Bundle unchanged, skipping publish", "digest", manifestDesc.Digest)
+		advanced, err := r.progressRollout(ctx, &mfe, time.Now())
+		if err != nil {
+			logger.Error(err, "Failed to progress rollout")
+			return r.fail(ctx, &mfe, err)
+		}
+		var backend cdn.Backend
+		var prefix string
+		if advanced {
+			backend, prefix, err = r.CDN.Resolve(mfe.Spec.CDNTarget)
+			if err != nil {
+				logger.Error(err, "Invalid CDN target")
+				return r.fail(ctx, &mfe, permanent(err))
+			}
+			if err := publishRollout(ctx, backend, prefix, &mfe); err != nil {
+				logger.Error(err, "Failed to publish rollout manifest")
+				return r.fail(ctx, &mfe, err)
+			}
+		}
+		checked := smokeCheckPending(&mfe)
+		if checked {
+			r.smokeCheck(ctx, &mfe)
+		}
+		if advanced || checked || mfe.Spec.UpdatePolicy != nil && mfe.Status.UpdatePolicy.LastCheckedAt != lastChecked {
+			// Persist the registry poll so the next requeue does not repeat it
+			if err := r.Status().Update(ctx, &mfe); err != nil {
+				logger.Error(err, "Failed to update MicroFrontend status")
+				return ctrl.Result{}, err
+			}
+		}
+		if advanced {
+			r.retire(ctx, &mfe, backend, prefix)
+		}
+		return ctrl.Result{RequeueAfter: r.requeueAfter(&mfe)}, nil
+	}
+
+	// Fetch and extract the artifact; unchanged layer digests are served from the cache
+	started := time.Now()
+	r.Recorder.Eventf(&mfe, corev1.EventTypeNormal, "FetchStarted", "Fetching %s (%s)", ref, manifestDesc.Digest)
+	art, err := bundle.NewArtifact(ctx, repo, manifestDesc)
+	if err != nil {
+		logger.Error(err, "Failed to resolve OCI artifact")
+		r.Recorder.Eventf(&mfe, corev1.EventTypeWarning, "FetchFailed", "Failed to resolve %s: %v", ref, err)
+		return r.fail(ctx, &mfe, err)
+	}
+	bundlePath, source, release, err := r.fetchBundle(ctx, &mfe, art)
+	if err != nil {
+		return r.fail(ctx, &mfe, err)
+	}
+	defer release()
+	logger.Info("Fetched bundle", "digest", art.Manifest.Digest, "path", bundlePath)
+	r.Recorder.Eventf(&mfe, corev1.EventTypeNormal, "FetchCompleted", "Fetched %s from %s in %s",
+		art.Manifest.Digest, source, time.Since(started).Round(time.Millisecond))
+	if source != "cache" {
+		stats, err := bundle.Stats(bundlePath)
+		if err != nil {
+			logger.Error(err, "Failed to inspect extracted bundle")
+			return r.fail(ctx, &mfe, err)
+		}
+		r.Recorder.Eventf(&mfe, corev1.EventTypeNormal, "Extracted", "Extracted %d files (%d bytes)", stats.Files, stats.Bytes)
+	}
+
+	// Refuse to publish bundles that do not satisfy the signature policies
+	verified, err := r.verifyArtifact(ctx, &mfe, art)
+	if err != nil {
+		logger.Error(err, "Failed to verify OCI artifact")
+		return r.fail(ctx, &mfe, err)
+	}
+	if !verified {
+		logger.Info("Refusing to publish unverified bundle", "digest", art.Manifest.Digest)
+		return r.refuse(ctx, &mfe, v1alpha1.ConditionVerified)
+	}
+	r.Recorder.Event(&mfe, corev1.EventTypeNormal, v1alpha1.ConditionVerified,
+		meta.FindStatusCondition(mfe.Status.Conditions, v1alpha1.ConditionVerified).Message)
+
+	// Summarize SBOM and provenance, enforcing any attestation requirements
+	attested, err := r.checkAttestations(ctx, &mfe, art)
+	if err != nil {
+		logger.Error(err, "Failed to check attestations")
+		return r.fail(ctx, &mfe, err)
+	}
+	if !attested {
+		logger.Info("Refusing to publish bundle without required attestations", "digest", art.Manifest.Digest)
+		return r.refuse(ctx, &mfe, v1alpha1.ConditionAttested)
+	}
+	r.Recorder.Event(&mfe, corev1.EventTypeNormal, v1alpha1.ConditionAttested,
+		meta.FindStatusCondition(mfe.Status.Conditions, v1alpha1.ConditionAttested).Message)
+
+	// Locate the entry point; Spec.EntryPoint may be a glob or name a hashed file
+	entry, err := module.ResolveEntryPoint(bundlePath, mfe.Spec.EntryPoint)
+	if err != nil {
+		logger.Error(err, "Failed to resolve entry point")
+		return r.fail(ctx, &mfe, permanent(err))
+	}
+
+	// Refuse to publish bundles that dropped a declared expose
+	exposed, err := r.checkExposes(ctx, &mfe, bundlePath, entry)
+	if err != nil {
+		logger.Error(err, "Failed to check exposed modules")
+		return r.fail(ctx, &mfe, err)
+	}
+	if !exposed {
+		logger.Info("Refusing to publish bundle with missing exposes", "digest", art.Manifest.Digest)
+		return r.refuse(ctx, &mfe, v1alpha1.ConditionModulesExposed)
+	}
+
+	// Refuse to overwrite a CDN prefix claimed by another MicroFrontend
+	owned, err := r.checkOwnership(ctx, &mfe)
+	if err != nil {
+		logger.Error(err, "Failed to check CDN prefix ownership")
+		return r.fail(ctx, &mfe, err)
+	}
+	if !owned {
+		logger.Info("Refusing to publish to a CDN prefix owned by another MicroFrontend", "target", mfe.Spec.CDNTarget)
+		return r.refuse(ctx, &mfe, v1alpha1.ConditionConflict)
+	}
+
+	// Publish the bundle, then the manifest that records which digest is live
+	backend, prefix, err := r.CDN.Resolve(mfe.Spec.CDNTarget)
+	if err != nil {
+		logger.Error(err, "Invalid CDN target")
+		return r.fail(ctx, &mfe, permanent(err))
+	}
+	// Under rollout each version gets its own prefix so the previous one stays live
+	uploadPrefix := prefix
+	if mfe.Spec.Rollout != nil {
+		uploadPrefix = rolloutVersionPrefix(prefix, art.Manifest.Digest.String())
+	}
+	started = time.Now()
+	uploaded, err := cdn.UploadDirectory(ctx, backend.Client, bundlePath, uploadPrefix)
+	if err != nil {
+		logger.Error(err, "Failed to upload bundle to CDN")
+		r.Recorder.Eventf(&mfe, corev1.EventTypeWarning, "UploadFailed", "Failed after uploading %d files to %s: %v", uploaded.Files, mfe.Spec.CDNTarget, err)
+		return r.fail(ctx, &mfe, err)
+	}
+	r.Recorder.Eventf(&mfe, corev1.EventTypeNormal, "Uploaded", "Uploaded %d files (%d bytes) to %s in %s",
+		uploaded.Files, uploaded.Bytes, backend.URL(uploadPrefix), time.Since(started).Round(time.Millisecond))
+	if mfe.Spec.Rollout != nil && mfe.Status.Rollout == nil && mfe.Status.Digest != "" {
+		// The version published before the rollout was enabled becomes stable
+		mfe.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: v1alpha1.RolloutVersion{
+			Digest:        mfe.Status.Digest,
+			EntryURL:      mfe.Status.EntryURL,
+			Tag:           versionTag(&mfe, mfe.Status.Digest),
+			SharedModules: vendorPaths(mfe.Status.SharedModules),
+		}}
+	}
+	if err := r.publishSharedModules(ctx, &mfe, backend, bundlePath, entry); err != nil {
+		logger.Error(err, "Failed to publish shared modules")
+		r.Recorder.Eventf(&mfe, corev1.EventTypeWarning, "SharedModulesFailed", "Failed to publish shared modules: %v", err)
+		return r.fail(ctx, &mfe, err)
+	}
+	if len(mfe.Status.SharedModules) > 0 {
+		logger.Info("Shared modules", "modules", sharedModuleNames(mfe.Status.SharedModules))
+		var published int
+		for _, m := range mfe.Status.SharedModules {
+			if m.Path != "" {
+				published++
+			}
+		}
+		r.Recorder.Eventf(&mfe, corev1.EventTypeNormal, "SharedModulesPublished", "Published %d of %d shared modules: %s",
+			published, len(mfe.Status.SharedModules), sharedModuleNames(mfe.Status.SharedModules))
+	}
+	now := time.Now().Format(time.RFC3339)
+	manifest := cdn.Manifest{Digest: art.Manifest.Digest.String(), PublishedAt: now}
+	if err := cdn.PublishManifest(ctx, backend.Client, prefix, manifest); err != nil {
+		logger.Error(err, "Failed to publish CDN manifest")
+		return r.fail(ctx, &mfe, err)
+	}
+	entryURL := backend.URL(path.Join(uploadPrefix, entry))
+	rollout := mfe.Spec.Rollout != nil || mfe.Status.Rollout != nil
+	if mfe.Spec.Rollout != nil {
+		version := v1alpha1.RolloutVersion{
+			Digest:   art.Manifest.Digest.String(),
+			EntryURL: entryURL,
+			Tag:      versionTag(&mfe, art.Manifest.Digest.String()),
+			// Recorded so vendor GC keeps them while the version is live
+			SharedModules: vendorPaths(mfe.Status.SharedModules),
+		}
+		startRollout(&mfe, version, time.Now())
+		if err := publishRollout(ctx, backend, prefix, &mfe); err != nil {
+			logger.Error(err, "Failed to publish rollout manifest")
+			return r.fail(ctx, &mfe, err)
+		}
+		logger.Info("Rollout", "phase", mfe.Status.Rollout.Phase, "weight", mfe.Status.Rollout.Weight)
+	} else {
+		mfe.Status.Rollout = nil
+		mfe.Status.EntryURL = entryURL
+	}
+
 	// Update status
 	mfe.Status.Synced = true
-	mfe.Status.LastSyncedAt = time.Now().Format(time.RFC3339)
-	mfe.Status.Message = "Successfully processed (simulated)"
+	mfe.Status.LastSyncedAt = now
+	previous := mfe.Status.Digest
+	mfe.Status.Digest = art.Manifest.Digest.String()
+	r.recordPromotion(&mfe, previous)
+	mfe.Status.ObservedGeneration = mfe.Generation
+	mfe.Status.Message = fmt.Sprintf("Published %s to %s", art.Manifest.Digest, mfe.Spec.CDNTarget)
+
+	// Confirm the CDN serves what was uploaded before reporting Ready
+	if err := planSmokeCheck(&mfe, backend, bundlePath, entry, uploadPrefix, art.Manifest.Digest.String()); err != nil {
+		logger.Error(err, "Failed to plan smoke check")
+		return r.fail(ctx, &mfe, err)
+	}
+	r.Recorder.Event(&mfe, corev1.EventTypeNormal, "Published", mfe.Status.Message)
+	r.smokeCheck(ctx, &mfe)
 	if err := r.Status().Update(ctx, &mfe); err != nil {
 		logger.Error(err, "Failed to update MicroFrontend status")
 		return ctrl.Result{}, err
 	}
+	if rollout {
+		r.retire(ctx, &mfe, backend, prefix)
+	}
+
+	return ctrl.Result{RequeueAfter: r.requeueAfter(&mfe)}, nil
+}
+
+// fetchBundle fetches and extracts the bundle layer of art and returns its
+// directory, where it came from, and the function that releases it. With a
+// cache the layer is loaded there and no workspace is acquired, so a cache
+// hit leaves the MicroFrontend's workspace untouched.
+func (r *MicroFrontendReconciler) fetchBundle(ctx context.Context, mfe *v1alpha1.MicroFrontend, art *bundle.Artifact) (string, string, func(), error) {
+	logger := log.FromContext(ctx)
+	if r.Cache != nil {
+		cached, err := r.Cache.Load(ctx, art.Repository, art.Layer)
+		if err != nil {
+			logger.Error(err, "Failed to fetch OCI artifact")
+			r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "FetchFailed", "Failed to fetch %s: %v", art.Manifest.Digest, err)
+			return "", "", nil, err
+		}
+		source := "registry"
+		if cached.Hit {
+			source = "cache"
+		}
+		return cached.Path, source, cached.Release, nil
+	}
 
-	return ctrl.Result{RequeueAfter: 10 * time.Minute}, nil
+	strategy := r.Workspaces.Strategy()
+	if mfe.Spec.WorkspaceStrategy != "" {
+		parsed, err := bundle.ParseTarballNamingStrategy(mfe.Spec.WorkspaceStrategy)
+		if err != nil {
+			logger.Error(err, "Invalid workspace strategy")
+			return "", "", nil, permanent(err)
+		}
+		strategy = parsed
+	}
+	ws, err := r.Workspaces.AcquireWithStrategy(mfe.Namespace, mfe.Name, strategy)
+	if err != nil {
+		logger.Error(err, "Failed to acquire workspace")
+		return "", "", nil, err
+	}
+	release := func() {
+		if err := ws.Close(); err != nil {
+			logger.Error(err, "Failed to clean up workspace")
+			r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "CleanupFailed", "Failed to remove workspace: %v", err)
+		} else {
+			r.Recorder.Event(mfe, corev1.EventTypeNormal, "CleanedUp", "Removed workspace")
+		}
+	}
+	tarballPath, err := ws.Fetch(ctx, art)
+	if err != nil {
+		release()
+		logger.Error(err, "Failed to fetch OCI artifact")
+		r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "FetchFailed", "Failed to fetch %s: %v", art.Manifest.Digest, err)
+		return "", "", nil, err
+	}
+	bundlePath, err := ws.Extract(ctx, tarballPath)
+	if err != nil {
+		release()
+		logger.Error(err, "Failed to extract OCI artifact")
+		r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "ExtractFailed", "Failed to extract %s: %v", art.Manifest.Digest, err)
+		return "", "", nil, err
+	}
+	return bundlePath, "registry", release, nil
+}
+
+// upToDate reports whether digest has already been published for the current
+// spec generation. With DriftCheck set, the CDN manifest must also still
+// record digest; any failure to read it counts as drift.
+func (r *MicroFrontendReconciler) upToDate(ctx context.Context, mfe *v1alpha1.MicroFrontend, digest string) bool {
+	status := mfe.Status
+	if !status.Synced || status.Digest != digest || status.ObservedGeneration != mfe.Generation {
+		return false
+	}
+	if !r.DriftCheck {
+		return true
+	}
+	backend, prefix, err := r.CDN.Resolve(mfe.Spec.CDNTarget)
+	if err != nil {
+		return false
+	}
+	published, err := cdn.FetchManifest(ctx, backend.Client, prefix)
+	if err != nil {
+		log.FromContext(ctx).Info("CDN drift check failed, republishing", "error", err.Error())
+		return false
+	}
+	return published.Digest == digest
+}
+
+// requeueAfter returns the delay until the next periodic reconcile: the
+// MicroFrontend's SyncInterval, or the operator's ResyncPeriod, shortened to
+// the registry poll interval when an update policy is set, to the end of
+// the current rollout step and to smokeCheckRetry while the smoke check
+// fails.
+func (r *MicroFrontendReconciler) requeueAfter(mfe *v1alpha1.MicroFrontend) time.Duration {
+	after := r.ResyncPeriod
+	if mfe.Spec.SyncInterval != nil && mfe.Spec.SyncInterval.Duration > 0 {
+		after = mfe.Spec.SyncInterval.Duration
+	}
+	if after <= 0 {
+		after = defaultResyncPeriod
+	}
+	if mfe.Spec.UpdatePolicy != nil && updateInterval(mfe) < after {
+		after = updateInterval(mfe)
+	}
+	if due := rolloutDue(mfe, time.Now()); due != 0 {
+		// An overdue step is retried shortly rather than in a tight loop
+		if due < time.Second {
+			due = time.Second
+		}
+		after = minDuration(after, due)
+	}
+	if smokeCheckRetryable(mfe) {
+		after = minDuration(after, smokeCheckRetry)
+	}
+	return after
+}
+
+// refuse marks the MicroFrontend as not synced, using the message of the
+// failed condition, records it as a Warning event and requeues it for the
+// next periodic check.
+func (r *MicroFrontendReconciler) refuse(ctx context.Context, mfe *v1alpha1.MicroFrontend, conditionType string) (ctrl.Result, error) {
+	c := meta.FindStatusCondition(mfe.Status.Conditions, conditionType)
+	r.Recorder.Event(mfe, corev1.EventTypeWarning, c.Reason, c.Message)
+	mfe.Status.Synced = false
+	mfe.Status.Message = c.Message
+	if err := r.Status().Update(ctx, mfe); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update MicroFrontend status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.requeueAfter(mfe)}, nil
+}
+
+// fail handles a pipeline error. Transient errors are returned so the work
+// queue retries them with backoff. Permanent ones are recorded in the status
+// and not retried before the next resync; a spec change triggers a reconcile
+// sooner.
+func (r *MicroFrontendReconciler) fail(ctx context.Context, mfe *v1alpha1.MicroFrontend, err error) (ctrl.Result, error) {
+	if !isPermanent(err) {
+		return ctrl.Result{}, err
+	}
+	mfe.Status.Synced = false
+	mfe.Status.Message = err.Error()
+	if err := r.Status().Update(ctx, mfe); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update MicroFrontend status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.requeueAfter(mfe)}, nil
 }
 
 func (r *MicroFrontendReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := IndexCDNTargets(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.MicroFrontend{}).
 		Complete(r)
@@ -0,0 +1,97 @@
+package controllers_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle"
+)
+
+// servedArtifact serves a bundle layer with the given files from a test
+// registry and returns the artifact that refers to it.
+func servedArtifact(t *testing.T, files map[string]string) *bundle.Artifact {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, buf.Bytes())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/mfe/checkout/blobs/"+layer.Digest.String() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", layer.Digest.String())
+		_, _ = w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	repo, err := remote.NewRepository(strings.TrimPrefix(server.URL, "http://") + "/mfe/checkout")
+	require.NoError(t, err)
+	repo.PlainHTTP = true
+	return &bundle.Artifact{Repository: repo, Layer: layer}
+}
+
+func TestFetchBundleFromCacheLeavesWorkspace(t *testing.T) {
+	base := t.TempDir()
+	workspaces, err := bundle.NewWorkspaceManager(base, bundle.UseCRName, 0)
+	require.NoError(t, err)
+	cache, err := bundle.NewBlobCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	r := &controllers.MicroFrontendReconciler{Recorder: record.NewFakeRecorder(10), Workspaces: workspaces, Cache: cache}
+	mfe := &v1alpha1.MicroFrontend{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}}
+	art := servedArtifact(t, map[string]string{"remoteEntry.js": "entry"})
+
+	// A cache-served reconcile must not wipe the MicroFrontend's workspace
+	marker := filepath.Join(base, "workspaces", bundle.SanitizeName("shop_checkout"), "marker")
+	require.NoError(t, os.MkdirAll(filepath.Dir(marker), 0o755))
+	require.NoError(t, os.WriteFile(marker, nil, 0o644))
+
+	for _, source := range []string{"registry", "cache"} {
+		path, got, release, err := controllers.FetchBundle(r, context.Background(), mfe, art)
+		require.NoError(t, err)
+		assert.Equal(t, source, got)
+		assert.FileExists(t, filepath.Join(path, "remoteEntry.js"))
+		release()
+	}
+	assert.FileExists(t, marker)
+}
+
+func TestFetchBundleThroughCacheKeepsWorkspaceLimit(t *testing.T) {
+	workspaces, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.IsolatedTempDir, 1024)
+	require.NoError(t, err)
+	cache, err := bundle.NewBlobCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	cache.ChargeLoadsTo(workspaces)
+	r := &controllers.MicroFrontendReconciler{Recorder: record.NewFakeRecorder(10), Workspaces: workspaces, Cache: cache}
+	mfe := &v1alpha1.MicroFrontend{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"}}
+
+	art := servedArtifact(t, map[string]string{"big.js": strings.Repeat("x", 2048)})
+	_, _, _, err = controllers.FetchBundle(r, context.Background(), mfe, art)
+	assert.True(t, errors.Is(err, bundle.ErrWorkspaceFull))
+}
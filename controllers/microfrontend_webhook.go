@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"oras.land/oras-go/v2/registry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// defaultEntryPoint is the Module Federation container entry file.
+const defaultEntryPoint = "remoteEntry.js"
+
+// exposedModulePattern matches Module Federation expose keys such as
+// "./Button" or "./widgets/Cart".
+var exposedModulePattern = regexp.MustCompile(`^\./[A-Za-z0-9_-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// MicroFrontendWebhook defaults and validates MicroFrontends at admission,
+// so that bad specs are rejected by the API server instead of failing in
+// Reconcile. The reconciler still reports conflicts that slip past it, e.g.
+// while the webhook is disabled.
+type MicroFrontendWebhook struct {
+	// Client finds MicroFrontends with overlapping CDN targets. It must be
+	// backed by a cache with the indexes from IndexCDNTargets.
+	Client client.Reader
+	// CDN is used to check that Spec.CDNTarget names a configured backend.
+	CDN cdn.Backends
+	// CatalogTarget is where the remote catalog is published, if anywhere.
+	CatalogTarget string
+}
+
+//+kubebuilder:webhook:path=/mutate-platform-mycorp-com-v1alpha1-microfrontend,mutating=true,failurePolicy=fail,sideEffects=None,groups=platform.mycorp.com,resources=microfrontends,verbs=create;update,versions=v1alpha1,name=mmicrofrontend.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-platform-mycorp-com-v1alpha1-microfrontend,mutating=false,failurePolicy=fail,sideEffects=None,groups=platform.mycorp.com,resources=microfrontends,verbs=create;update,versions=v1alpha1,name=vmicrofrontend.kb.io,admissionReviewVersions=v1
+
+func (w *MicroFrontendWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.MicroFrontend{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter.
+func (w *MicroFrontendWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	mfe, ok := obj.(*v1alpha1.MicroFrontend)
+	if !ok {
+		return fmt.Errorf("expected a MicroFrontend but got %T", obj)
+	}
+	if mfe.Spec.EntryPoint == "" {
+		mfe.Spec.EntryPoint = defaultEntryPoint
+	}
+	return nil
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (w *MicroFrontendWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return w.validate(ctx, obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (w *MicroFrontendWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return w.validate(ctx, newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (w *MicroFrontendWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func (w *MicroFrontendWebhook) validate(ctx context.Context, obj runtime.Object) error {
+	mfe, ok := obj.(*v1alpha1.MicroFrontend)
+	if !ok {
+		return fmt.Errorf("expected a MicroFrontend but got %T", obj)
+	}
+	errs := w.validateSpec(&mfe.Spec, field.NewPath("spec"))
+
+	// Reject targets that overlap a reserved prefix or one another
+	// MicroFrontend publishes to
+	reserved, err := reservedBy(ctx, w.Client, mfe, w.CatalogTarget)
+	if err != nil {
+		return err
+	}
+	if reserved != "" {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "cdnTarget"), "overlaps "+reserved))
+	}
+	conflicts, err := findConflicts(ctx, w.Client, mfe)
+	if err != nil {
+		return err
+	}
+	for _, other := range conflicts {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "cdnTarget"),
+			fmt.Sprintf("overlaps %s of MicroFrontend %s/%s", other.Spec.CDNTarget, other.Namespace, other.Name)))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("MicroFrontend").GroupKind(), mfe.Name, errs)
+}
+
+func (w *MicroFrontendWebhook) validateSpec(spec *v1alpha1.MicroFrontendSpec, p *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if ref, err := registry.ParseReference(spec.OCIArtifact); err != nil {
+		errs = append(errs, field.Invalid(p.Child("ociArtifact"), spec.OCIArtifact, err.Error()))
+	} else if ref.Reference == "" && spec.UpdatePolicy == nil {
+		errs = append(errs, field.Invalid(p.Child("ociArtifact"), spec.OCIArtifact, "must include a tag or digest unless updatePolicy is set"))
+	}
+
+	if _, _, err := w.CDN.Resolve(spec.CDNTarget); err != nil {
+		errs = append(errs, field.Invalid(p.Child("cdnTarget"), spec.CDNTarget, err.Error()))
+	}
+
+	entry := spec.EntryPoint
+	switch {
+	case entry == "":
+		errs = append(errs, field.Required(p.Child("entryPoint"), ""))
+	case path.IsAbs(entry) || strings.Contains(entry, `\`):
+		errs = append(errs, field.Invalid(p.Child("entryPoint"), entry, "must be a relative path"))
+	case containsDotDot(entry):
+		errs = append(errs, field.Invalid(p.Child("entryPoint"), entry, "must not contain '..'"))
+	}
+
+	seen := map[string]bool{}
+	for i, m := range spec.ExposedModules {
+		mp := p.Child("exposedModules").Index(i)
+		if !exposedModulePattern.MatchString(m) || containsDotDot(m) {
+			errs = append(errs, field.Invalid(mp, m, `must have the form "./Name"`))
+		}
+		if seen[m] {
+			errs = append(errs, field.Duplicate(mp, m))
+		}
+		seen[m] = true
+	}
+
+	if up := spec.UpdatePolicy; up != nil {
+		if _, err := bundle.NewTagPolicy(up.SemVer, up.TagPattern); err != nil {
+			errs = append(errs, field.Invalid(p.Child("updatePolicy"), up, err.Error()))
+		}
+	}
+	return errs
+}
+
+// containsDotDot reports whether any element of the slash-separated path p
+// is "..".
+func containsDotDot(p string) bool {
+	for _, elem := range strings.Split(p, "/") {
+		if elem == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package controllers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// builderIndexer registers field indexes on a fake client builder.
+type builderIndexer struct {
+	*fake.ClientBuilder
+}
+
+func (b builderIndexer) IndexField(ctx context.Context, obj client.Object, field string, fn client.IndexerFunc) error {
+	b.WithIndex(obj, field, fn)
+	return nil
+}
+
+func newWebhook(t *testing.T, existing ...client.Object) *controllers.MicroFrontendWebhook {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing...)
+	require.NoError(t, controllers.IndexCDNTargets(context.Background(), builderIndexer{builder}))
+	return &controllers.MicroFrontendWebhook{Client: builder.Build(), CDN: cdn.Backends{"primary": {}}}
+}
+
+func validSpec() v1alpha1.MicroFrontendSpec {
+	return v1alpha1.MicroFrontendSpec{
+		OCIArtifact:    "registry.example.com/mfe/checkout:v1.2.0",
+		CDNTarget:      "primary/apps/checkout",
+		EntryPoint:     "remoteEntry.js",
+		ExposedModules: []string{"./Cart", "./widgets/MiniCart"},
+	}
+}
+
+func TestWebhookDefaultsEntryPoint(t *testing.T) {
+	mfe := &v1alpha1.MicroFrontend{}
+	require.NoError(t, newWebhook(t).Default(context.Background(), mfe))
+	assert.Equal(t, "remoteEntry.js", mfe.Spec.EntryPoint)
+}
+
+func TestWebhookValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(*v1alpha1.MicroFrontendSpec)
+		field  string
+	}{
+		{name: "valid", mutate: func(*v1alpha1.MicroFrontendSpec) {}},
+		{name: "unparseable reference", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.OCIArtifact = "not a ref" }, field: "spec.ociArtifact"},
+		{name: "missing tag", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.OCIArtifact = "registry.example.com/mfe/checkout" }, field: "spec.ociArtifact"},
+		{name: "unknown backend", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.CDNTarget = "secondary/apps/checkout" }, field: "spec.cdnTarget"},
+		{name: "absolute entry point", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.EntryPoint = "/remoteEntry.js" }, field: "spec.entryPoint"},
+		{name: "entry point escapes bundle", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.EntryPoint = "js/../../remoteEntry.js" }, field: "spec.entryPoint"},
+		{name: "malformed module", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.ExposedModules = []string{"Cart"} }, field: "spec.exposedModules[0]"},
+		{name: "duplicate module", mutate: func(s *v1alpha1.MicroFrontendSpec) { s.ExposedModules = []string{"./Cart", "./Cart"} }, field: "spec.exposedModules[1]"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mfe := &v1alpha1.MicroFrontend{Spec: validSpec()}
+			tc.mutate(&mfe.Spec)
+			err := newWebhook(t).ValidateCreate(context.Background(), mfe)
+			if tc.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), "got %v", err)
+			assert.Contains(t, err.Error(), tc.field)
+		})
+	}
+}
+
+func TestWebhookRejectsOverlappingTargets(t *testing.T) {
+	existing := &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "checkout"},
+		Spec:       validSpec(),
+	}
+	webhook := newWebhook(t, existing)
+
+	cases := []struct {
+		target   string
+		conflict bool
+	}{
+		{target: "primary/apps/checkout", conflict: true},
+		{target: "primary/apps/checkout/", conflict: true},
+		{target: "primary/apps", conflict: true},
+		{target: "primary/apps/checkout/v2", conflict: true},
+		{target: "primary/apps/checkout2", conflict: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.target, func(t *testing.T) {
+			mfe := &v1alpha1.MicroFrontend{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "checkout"},
+				Spec:       validSpec(),
+			}
+			mfe.Spec.CDNTarget = tc.target
+			err := webhook.ValidateCreate(context.Background(), mfe)
+			if !tc.conflict {
+				assert.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), "got %v", err)
+			assert.Contains(t, err.Error(), "team-a/checkout")
+		})
+	}
+
+	// Updating the owner itself is not a conflict
+	assert.NoError(t, webhook.ValidateUpdate(context.Background(), existing, existing))
+}
+
+func TestWebhookRejectsReservedTargets(t *testing.T) {
+	host := &v1alpha1.MicroFrontendHost{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "storefront"},
+		Spec:       v1alpha1.MicroFrontendHostSpec{CDNTarget: "primary/hosts/storefront"},
+	}
+	webhook := newWebhook(t, host)
+	webhook.CatalogTarget = "primary/catalog"
+
+	cases := []struct {
+		target   string
+		reserved string
+	}{
+		{target: "primary/vendor", reserved: "shared module directory"},
+		{target: "primary/vendor/react@18.2.0", reserved: "shared module directory"},
+		{target: "primary/catalog", reserved: "remote catalog"},
+		{target: "primary/hosts/storefront", reserved: "shop/storefront"},
+		{target: "primary/hosts", reserved: "shop/storefront"},
+		{target: "primary/.", reserved: `"." path segments`},
+		{target: "primary/apps/..", reserved: `".." path segments`},
+		{target: "primary/apps/../vendor", reserved: `".." path segments`},
+		{target: "primary/catalog/checkout"},
+		{target: "primary/hosts/storefront/checkout"},
+		{target: "primary/vendors"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.target, func(t *testing.T) {
+			mfe := &v1alpha1.MicroFrontend{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "checkout"},
+				Spec:       validSpec(),
+			}
+			mfe.Spec.CDNTarget = tc.target
+			err := webhook.ValidateCreate(context.Background(), mfe)
+			if tc.reserved == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), "got %v", err)
+			assert.Contains(t, err.Error(), tc.reserved)
+		})
+	}
+}
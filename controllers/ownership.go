@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// Field indexes over MicroFrontends used to find CDN prefix conflicts.
+// cdnTargetIndex holds the normalized target; cdnPrefixIndex holds the target
+// and every ancestor of it, so that nested prefixes are found as well.
+const (
+	cdnTargetIndex = "spec.cdnTarget"
+	cdnPrefixIndex = "spec.cdnTarget.prefixes"
+)
+
+// IndexCDNTargets registers the CDN target indexes with the manager's cache.
+func IndexCDNTargets(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &v1alpha1.MicroFrontend{}, cdnTargetIndex, func(obj client.Object) []string {
+		if key := cdnTargetKey(obj.(*v1alpha1.MicroFrontend).Spec.CDNTarget); key != "" {
+			return []string{key}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to index %s: %w", cdnTargetIndex, err)
+	}
+	if err := indexer.IndexField(ctx, &v1alpha1.MicroFrontend{}, cdnPrefixIndex, func(obj client.Object) []string {
+		return prefixKeys(cdnTargetKey(obj.(*v1alpha1.MicroFrontend).Spec.CDNTarget))
+	}); err != nil {
+		return fmt.Errorf("failed to index %s: %w", cdnPrefixIndex, err)
+	}
+	return nil
+}
+
+// cdnTargetKey normalizes a CDN target to "<backend>/<prefix>", or returns
+// "" if the target is malformed.
+func cdnTargetKey(target string) string {
+	backend, prefix, err := cdn.SplitTarget(target)
+	if err != nil {
+		return ""
+	}
+	return backend + "/" + path.Clean(prefix)
+}
+
+// prefixKeys returns key and each of its ancestors below the backend, e.g.
+// "cdn/a/b" yields "cdn/a/b" and "cdn/a".
+func prefixKeys(key string) []string {
+	var keys []string
+	for strings.Count(key, "/") > 0 {
+		keys = append(keys, key)
+		key = path.Dir(key)
+	}
+	return keys
+}
+
+// findConflicts returns the other MicroFrontends whose CDN target equals,
+// contains or is contained in mfe's.
+func findConflicts(ctx context.Context, reader client.Reader, mfe *v1alpha1.MicroFrontend) ([]v1alpha1.MicroFrontend, error) {
+	key := cdnTargetKey(mfe.Spec.CDNTarget)
+	if key == "" {
+		return nil, nil
+	}
+
+	seen := map[client.ObjectKey]bool{client.ObjectKeyFromObject(mfe): true}
+	var conflicts []v1alpha1.MicroFrontend
+	collect := func(index, value string) error {
+		var list v1alpha1.MicroFrontendList
+		if err := reader.List(ctx, &list, client.MatchingFields{index: value}); err != nil {
+			return fmt.Errorf("failed to list MicroFrontends by CDN target: %w", err)
+		}
+		for _, other := range list.Items {
+			k := client.ObjectKeyFromObject(&other)
+			if !seen[k] {
+				seen[k] = true
+				conflicts = append(conflicts, other)
+			}
+		}
+		return nil
+	}
+
+	// Targets at or below ours, then targets above ours
+	if err := collect(cdnPrefixIndex, key); err != nil {
+		return nil, err
+	}
+	for _, ancestor := range prefixKeys(key)[1:] {
+		if err := collect(cdnTargetIndex, ancestor); err != nil {
+			return nil, err
+		}
+	}
+	return conflicts, nil
+}
+
+// reservedBy returns what reserves part of mfe's CDN target, or "" if
+// nothing does. The vendor/ directory at the backend root holds shared
+// modules and may not overlap any target. The remote catalog at
+// catalogTarget and the manifests of MicroFrontendHosts are single files,
+// which a bundle published to the same prefix or above it could overwrite.
+func reservedBy(ctx context.Context, reader client.Reader, mfe *v1alpha1.MicroFrontend, catalogTarget string) (string, error) {
+	key := cdnTargetKey(mfe.Spec.CDNTarget)
+	if key == "" {
+		return "", nil
+	}
+	backend, _, _ := strings.Cut(key, "/")
+	if vendor := backend + "/vendor"; overlaps(key, vendor) {
+		return "the shared module directory " + vendor, nil
+	}
+	if catalog := cdnTargetKey(catalogTarget); catalog != "" && contains(key, catalog) {
+		return "the remote catalog at " + catalogTarget, nil
+	}
+	var hosts v1alpha1.MicroFrontendHostList
+	if err := reader.List(ctx, &hosts); err != nil {
+		return "", fmt.Errorf("failed to list MicroFrontendHosts: %w", err)
+	}
+	for _, host := range hosts.Items {
+		if target := cdnTargetKey(host.Spec.CDNTarget); target != "" && contains(key, target) {
+			return fmt.Sprintf("the manifest of MicroFrontendHost %s/%s at %s", host.Namespace, host.Name, host.Spec.CDNTarget), nil
+		}
+	}
+	return "", nil
+}
+
+// contains reports whether the prefix key equals or contains other.
+func contains(key, other string) bool {
+	return key == other || strings.HasPrefix(other, key+"/")
+}
+
+// overlaps reports whether one of the prefixes a and b contains the other.
+func overlaps(a, b string) bool {
+	return contains(a, b) || contains(b, a)
+}
+
+// claimedBefore reports whether a holds its CDN prefix ahead of b: the older
+// MicroFrontend wins, ties broken by namespace/name.
+func claimedBefore(a, b *v1alpha1.MicroFrontend) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return client.ObjectKeyFromObject(a).String() < client.ObjectKeyFromObject(b).String()
+}
+
+// checkOwnership records whether mfe owns its CDN prefix as the Conflict
+// condition. It returns false when the prefix is reserved or another
+// MicroFrontend claimed an overlapping prefix first, in which case the
+// bundle must not be published.
+func (r *MicroFrontendReconciler) checkOwnership(ctx context.Context, mfe *v1alpha1.MicroFrontend) (bool, error) {
+	if _, _, err := cdn.SplitTarget(mfe.Spec.CDNTarget); err != nil {
+		return false, permanent(err)
+	}
+	reserved, err := reservedBy(ctx, r.Client, mfe, r.CatalogTarget)
+	if err != nil {
+		return false, err
+	}
+	if reserved != "" {
+		setConflict(mfe, metav1.ConditionTrue, "PrefixReserved",
+			fmt.Sprintf("CDN target %s overlaps %s", mfe.Spec.CDNTarget, reserved))
+		return false, nil
+	}
+	conflicts, err := findConflicts(ctx, r.Client, mfe)
+	if err != nil {
+		return false, err
+	}
+	for i := range conflicts {
+		owner := &conflicts[i]
+		if claimedBefore(owner, mfe) {
+			setConflict(mfe, metav1.ConditionTrue, "PrefixClaimed",
+				fmt.Sprintf("CDN target %s overlaps %s of MicroFrontend %s/%s", mfe.Spec.CDNTarget, owner.Spec.CDNTarget, owner.Namespace, owner.Name))
+			return false, nil
+		}
+	}
+	setConflict(mfe, metav1.ConditionFalse, "PrefixOwned", "CDN target "+mfe.Spec.CDNTarget+" is not claimed by another MicroFrontend")
+	return true, nil
+}
+
+func setConflict(mfe *v1alpha1.MicroFrontend, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mfe.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionConflict,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mfe.Generation,
+	})
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"oras.land/oras-go/v2/registry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"mfe-operator/api/v1alpha1"
+)
+
+// PromotionReconciler advances the digest a MicroFrontend serves through
+// the stages of each Promotion. Every stage is published by a MicroFrontend
+// owned by the Promotion and pinned to the digest, so stages go through the
+// same verification, publishing and smoke checks as any other bundle.
+type PromotionReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=promotions,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=promotions/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=promotions/finalizers,verbs=update
+
+func (r *PromotionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var promo v1alpha1.Promotion
+	if err := r.Get(ctx, req.NamespacedName, &promo); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	before := promo.Status.DeepCopy()
+	promo.Status.Stages = stageStatuses(promo.Spec.Stages, promo.Status.Stages)
+	if err := r.pruneStages(ctx, &promo); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var src v1alpha1.MicroFrontend
+	err := r.Get(ctx, types.NamespacedName{Namespace: promo.Namespace, Name: promo.Spec.MicroFrontend}, &src)
+	if apierrors.IsNotFound(err) {
+		promo.Status.Message = fmt.Sprintf("MicroFrontend %s not found", promo.Spec.MicroFrontend)
+		return ctrl.Result{}, r.updatePromotionStatus(ctx, &promo, before)
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get MicroFrontend: %w", err)
+	}
+	digest := promotableDigest(&src)
+	if digest == "" {
+		promo.Status.Message = fmt.Sprintf("Waiting for MicroFrontend %s to publish a bundle", src.Name)
+		return ctrl.Result{}, r.updatePromotionStatus(ctx, &promo, before)
+	}
+	artifact, err := pinnedArtifact(src.Spec.OCIArtifact, digest)
+	if err != nil {
+		promo.Status.Message = err.Error()
+		return ctrl.Result{}, r.updatePromotionStatus(ctx, &promo, before)
+	}
+	promo.Status.Message = ""
+
+	if digest != promo.Status.Digest {
+		r.Recorder.Eventf(&promo, corev1.EventTypeNormal, "Promoting", "Promoting %s from MicroFrontend %s", digest, src.Name)
+		promo.Status.Digest = digest
+		for i := range promo.Status.Stages {
+			st := &promo.Status.Stages[i]
+			if st.Phase != v1alpha1.StageSucceeded || st.Digest != digest {
+				*st = v1alpha1.PromotionStageStatus{Name: st.Name, Phase: v1alpha1.StagePending, Digest: st.Digest, MicroFrontend: st.MicroFrontend}
+			}
+		}
+	}
+
+	promo.Status.CurrentStage = ""
+	for i, stage := range promo.Spec.Stages {
+		st := &promo.Status.Stages[i]
+		if st.Phase == v1alpha1.StageSucceeded && st.Digest == digest {
+			continue
+		}
+		promo.Status.CurrentStage = stage.Name
+		done, err := r.advanceStage(ctx, &promo, &src, stage, st, artifact)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !done {
+			break
+		}
+		promo.Status.CurrentStage = ""
+	}
+	return ctrl.Result{}, r.updatePromotionStatus(ctx, &promo, before)
+}
+
+// advanceStage moves a stage that has not yet succeeded with the promoted
+// digest forward: it waits for approval if required, publishes the stage
+// and checks whether it has been published. It reports whether the stage
+// succeeded.
+func (r *PromotionReconciler) advanceStage(ctx context.Context, promo *v1alpha1.Promotion, src *v1alpha1.MicroFrontend,
+	stage v1alpha1.PromotionStage, st *v1alpha1.PromotionStageStatus, artifact string) (bool, error) {
+	now := time.Now().Format(time.RFC3339)
+	digest := promo.Status.Digest
+
+	if stage.RequireApproval && st.ApprovedDigest != digest {
+		approval, annotated := promo.Annotations[v1alpha1.PromotionApproveAnnotation]
+		name, approved, _ := strings.Cut(approval, "@")
+		if name == stage.Name && approved != digest {
+			// An approval for another digest must not carry over to this one
+			if err := r.consumeApproval(ctx, promo); err != nil {
+				return false, err
+			}
+			r.Recorder.Eventf(promo, corev1.EventTypeWarning, "ApprovalIgnored",
+				"Ignoring approval %q of stage %s; %s is being promoted", approval, stage.Name, digest)
+			annotated = false
+		}
+		if !annotated || name != stage.Name {
+			if st.Phase != v1alpha1.StageWaitingForApproval {
+				r.Recorder.Eventf(promo, corev1.EventTypeNormal, "WaitingForApproval", "Stage %s is waiting for approval of %s", stage.Name, digest)
+			}
+			st.Phase = v1alpha1.StageWaitingForApproval
+			st.Message = fmt.Sprintf("Annotate with %s=%s@%s to approve", v1alpha1.PromotionApproveAnnotation, stage.Name, digest)
+			return false, nil
+		}
+		if err := r.consumeApproval(ctx, promo); err != nil {
+			return false, err
+		}
+		st.ApprovedAt = now
+		st.ApprovedDigest = digest
+		r.Recorder.Eventf(promo, corev1.EventTypeNormal, "Approved", "Stage %s approved for %s", stage.Name, digest)
+	}
+
+	child, err := r.ensureStage(ctx, promo, src, stage, artifact)
+	if err != nil {
+		return false, err
+	}
+	st.MicroFrontend = child.Name
+	if st.Phase != v1alpha1.StageProgressing {
+		st.Phase = v1alpha1.StageProgressing
+		st.StartedAt = now
+		st.CompletedAt = ""
+		r.Recorder.Eventf(promo, corev1.EventTypeNormal, "StagePublishing", "Publishing %s to stage %s (%s)", digest, stage.Name, stage.CDNTarget)
+	}
+	if !stagePublished(child, digest) {
+		st.Message = child.Status.Message
+		return false, nil
+	}
+
+	st.Phase = v1alpha1.StageSucceeded
+	st.Digest = digest
+	st.CompletedAt = now
+	st.Message = ""
+	r.Recorder.Eventf(promo, corev1.EventTypeNormal, "StageSucceeded", "Stage %s serves %s", stage.Name, digest)
+	return true, nil
+}
+
+// ensureStage creates or updates the MicroFrontend publishing stage. It
+// carries the source's labels, so that a catalog selector listing the source
+// also lists its stages, and the source's remote name, so that hosts load
+// every stage under the name they load the source by; the stage label tells
+// the stages apart.
+func (r *PromotionReconciler) ensureStage(ctx context.Context, promo *v1alpha1.Promotion, src *v1alpha1.MicroFrontend,
+	stage v1alpha1.PromotionStage, artifact string) (*v1alpha1.MicroFrontend, error) {
+	spec := v1alpha1.MicroFrontendSpec{
+		OCIArtifact:       artifact,
+		CDNTarget:         stage.CDNTarget,
+		EntryPoint:        src.Spec.EntryPoint,
+		ExposedModules:    src.Spec.ExposedModules,
+		WorkspaceStrategy: src.Spec.WorkspaceStrategy,
+		SyncInterval:      src.Spec.SyncInterval,
+		SmokeCheck:        stage.SmokeCheck,
+	}
+
+	labels := map[string]string{}
+	for k, v := range src.Labels {
+		labels[k] = v
+	}
+	labels[v1alpha1.PromotionStageLabel] = stage.Name
+	annotations := map[string]string{v1alpha1.RemoteNameAnnotation: remoteName(src)}
+
+	var child v1alpha1.MicroFrontend
+	key := types.NamespacedName{Namespace: promo.Namespace, Name: promo.Name + "-" + stage.Name}
+	err := r.Get(ctx, key, &child)
+	if apierrors.IsNotFound(err) {
+		child = v1alpha1.MicroFrontend{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   key.Namespace,
+				Name:        key.Name,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: spec,
+		}
+		if err := controllerutil.SetControllerReference(promo, &child, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set owner of MicroFrontend %s: %w", key, err)
+		}
+		if err := r.Create(ctx, &child); err != nil {
+			return nil, fmt.Errorf("failed to create MicroFrontend %s: %w", key, err)
+		}
+		return &child, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get MicroFrontend %s: %w", key, err)
+	}
+
+	if !metav1.IsControlledBy(&child, promo) {
+		return nil, fmt.Errorf("MicroFrontend %s exists and is not owned by Promotion %s", key, promo.Name)
+	}
+	changed := !equality.Semantic.DeepEqual(child.Spec, spec)
+	child.Spec = spec
+	changed = mergeMetadata(&child.Labels, labels) || changed
+	changed = mergeMetadata(&child.Annotations, annotations) || changed
+	if !changed {
+		return &child, nil
+	}
+	if err := r.Update(ctx, &child); err != nil {
+		return nil, fmt.Errorf("failed to update MicroFrontend %s: %w", key, err)
+	}
+	return &child, nil
+}
+
+// mergeMetadata sets the entries of want in *m, keeping any others, and
+// reports whether that changed it.
+func mergeMetadata(m *map[string]string, want map[string]string) bool {
+	changed := false
+	for k, v := range want {
+		if value, ok := (*m)[k]; ok && value == v {
+			continue
+		}
+		if *m == nil {
+			*m = map[string]string{}
+		}
+		(*m)[k] = v
+		changed = true
+	}
+	return changed
+}
+
+// pruneStages deletes the MicroFrontends promo created for stages that are
+// no longer in its spec.
+func (r *PromotionReconciler) pruneStages(ctx context.Context, promo *v1alpha1.Promotion) error {
+	var list v1alpha1.MicroFrontendList
+	if err := r.List(ctx, &list, client.InNamespace(promo.Namespace), client.HasLabels{v1alpha1.PromotionStageLabel}); err != nil {
+		return fmt.Errorf("failed to list stage MicroFrontends: %w", err)
+	}
+	stages := map[string]bool{}
+	for _, stage := range promo.Spec.Stages {
+		stages[stage.Name] = true
+	}
+	for i := range list.Items {
+		child := &list.Items[i]
+		stage := child.Labels[v1alpha1.PromotionStageLabel]
+		if stages[stage] || !metav1.IsControlledBy(child, promo) || !child.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete MicroFrontend %s: %w", child.Name, err)
+		}
+		r.Recorder.Eventf(promo, corev1.EventTypeNormal, "StageRemoved", "Deleted MicroFrontend %s of removed stage %s", child.Name, stage)
+	}
+	return nil
+}
+
+// consumeApproval removes the approval annotation, updating a copy so that
+// pending status changes survive the update.
+func (r *PromotionReconciler) consumeApproval(ctx context.Context, promo *v1alpha1.Promotion) error {
+	obj := promo.DeepCopy()
+	delete(obj.Annotations, v1alpha1.PromotionApproveAnnotation)
+	if err := r.Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to remove approval annotation: %w", err)
+	}
+	promo.Annotations, promo.ResourceVersion = obj.Annotations, obj.ResourceVersion
+	return nil
+}
+
+// stageStatuses returns a status for every stage, keeping the existing
+// ones by name.
+func stageStatuses(stages []v1alpha1.PromotionStage, existing []v1alpha1.PromotionStageStatus) []v1alpha1.PromotionStageStatus {
+	byName := map[string]v1alpha1.PromotionStageStatus{}
+	for _, st := range existing {
+		byName[st.Name] = st
+	}
+	statuses := make([]v1alpha1.PromotionStageStatus, 0, len(stages))
+	for _, stage := range stages {
+		st, ok := byName[stage.Name]
+		if !ok {
+			st = v1alpha1.PromotionStageStatus{Name: stage.Name, Phase: v1alpha1.StagePending}
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// promotableDigest returns the digest src serves and has published for its
+// current spec, or "" if there is none yet. During a rollout that is the
+// stable version's digest.
+func promotableDigest(src *v1alpha1.MicroFrontend) string {
+	if !src.Status.Synced || src.Status.ObservedGeneration != src.Generation {
+		return ""
+	}
+	if meta.IsStatusConditionFalse(src.Status.Conditions, v1alpha1.ConditionReady) {
+		return ""
+	}
+	return liveDigest(src)
+}
+
+// pinnedArtifact returns the reference of the repository of artifact at
+// digest.
+func pinnedArtifact(artifact, digest string) (string, error) {
+	ref, err := registry.ParseReference(artifact)
+	if err != nil {
+		return "", fmt.Errorf("invalid OCI artifact %q: %w", artifact, err)
+	}
+	return ref.Registry + "/" + ref.Repository + "@" + digest, nil
+}
+
+// stagePublished reports whether child has published digest for its
+// current spec and passed its smoke check.
+func stagePublished(child *v1alpha1.MicroFrontend, digest string) bool {
+	if !child.Status.Synced || child.Status.Digest != digest || child.Status.ObservedGeneration != child.Generation {
+		return false
+	}
+	ready := meta.FindStatusCondition(child.Status.Conditions, v1alpha1.ConditionReady)
+	return ready != nil && ready.Status == metav1.ConditionTrue && ready.ObservedGeneration == child.Generation
+}
+
+func (r *PromotionReconciler) updatePromotionStatus(ctx context.Context, promo *v1alpha1.Promotion, before *v1alpha1.PromotionStatus) error {
+	if equality.Semantic.DeepEqual(&promo.Status, before) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, promo); err != nil {
+		return fmt.Errorf("failed to update Promotion status: %w", err)
+	}
+	return nil
+}
+
+// SetupWithManager also reconciles the Promotions of a MicroFrontend when
+// it publishes, and a Promotion when one of its stages changes.
+func (r *PromotionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	promotions := func(obj client.Object) []reconcile.Request {
+		var list v1alpha1.PromotionList
+		if err := r.List(context.Background(), &list, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, promo := range list.Items {
+			if promo.Spec.MicroFrontend == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&promo)})
+			}
+		}
+		return requests
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Promotion{}).
+		Owns(&v1alpha1.MicroFrontend{}).
+		Watches(&source.Kind{Type: &v1alpha1.MicroFrontend{}}, handler.EnqueueRequestsFromMapFunc(promotions)).
+		Complete(r)
+}
@@ -0,0 +1,188 @@
+package controllers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+)
+
+func TestPromotion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	ctx := context.Background()
+	src := &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec: v1alpha1.MicroFrontendSpec{
+			OCIArtifact: "registry.example.com/mfe/checkout:v1.2.0",
+			CDNTarget:   "dev/checkout",
+			EntryPoint:  "remoteEntry.js",
+		},
+		Status: v1alpha1.MicroFrontendStatus{Synced: true, Digest: "sha256:aaa"},
+	}
+	promo := &v1alpha1.Promotion{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec: v1alpha1.PromotionSpec{
+			MicroFrontend: "checkout",
+			Stages: []v1alpha1.PromotionStage{
+				{Name: "staging", CDNTarget: "staging/checkout"},
+				{Name: "prod", CDNTarget: "prod/checkout", RequireApproval: true},
+			},
+		},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(src, promo).Build()
+	r := &controllers.PromotionReconciler{Client: k8s, Scheme: scheme, Recorder: record.NewFakeRecorder(20)}
+
+	reconcile := func() *v1alpha1.Promotion {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(promo)})
+		require.NoError(t, err)
+		var got v1alpha1.Promotion
+		require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(promo), &got))
+		return &got
+	}
+	// publish simulates the MicroFrontend controller publishing a stage
+	publish := func(name string) *v1alpha1.MicroFrontend {
+		var child v1alpha1.MicroFrontend
+		require.NoError(t, k8s.Get(ctx, types.NamespacedName{Namespace: "shop", Name: name}, &child))
+		_, digest, _ := strings.Cut(child.Spec.OCIArtifact, "@")
+		child.Status.Synced = true
+		child.Status.Digest = digest
+		child.Status.ObservedGeneration = child.Generation
+		meta.SetStatusCondition(&child.Status.Conditions, metav1.Condition{
+			Type: v1alpha1.ConditionReady, Status: metav1.ConditionTrue, Reason: "SmokeCheckPassed", ObservedGeneration: child.Generation,
+		})
+		require.NoError(t, k8s.Status().Update(ctx, &child))
+		return &child
+	}
+
+	// The first stage is published by a MicroFrontend pinned to the digest
+	got := reconcile()
+	assert.Equal(t, "sha256:aaa", got.Status.Digest)
+	assert.Equal(t, "staging", got.Status.CurrentStage)
+	assert.Equal(t, v1alpha1.StageProgressing, got.Status.Stages[0].Phase)
+	staging := publish("checkout-staging")
+	assert.Equal(t, "registry.example.com/mfe/checkout@sha256:aaa", staging.Spec.OCIArtifact)
+	assert.Equal(t, "staging/checkout", staging.Spec.CDNTarget)
+	assert.True(t, metav1.IsControlledBy(staging, got))
+
+	// Production waits for approval
+	got = reconcile()
+	assert.Equal(t, v1alpha1.StageSucceeded, got.Status.Stages[0].Phase)
+	assert.NotEmpty(t, got.Status.Stages[0].CompletedAt)
+	assert.Equal(t, v1alpha1.StageWaitingForApproval, got.Status.Stages[1].Phase)
+	assert.Equal(t, "prod", got.Status.CurrentStage)
+	var prod v1alpha1.MicroFrontend
+	assert.True(t, apierrors.IsNotFound(k8s.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "checkout-prod"}, &prod)))
+
+	// An approval that does not name the promoted digest is discarded
+	got.Annotations = map[string]string{v1alpha1.PromotionApproveAnnotation: "prod"}
+	require.NoError(t, k8s.Update(ctx, got))
+	got = reconcile()
+	assert.NotContains(t, got.Annotations, v1alpha1.PromotionApproveAnnotation)
+	assert.Equal(t, v1alpha1.StageWaitingForApproval, got.Status.Stages[1].Phase)
+	assert.Equal(t, "Annotate with "+v1alpha1.PromotionApproveAnnotation+"=prod@sha256:aaa to approve", got.Status.Stages[1].Message)
+
+	got.Annotations = map[string]string{v1alpha1.PromotionApproveAnnotation: "prod@sha256:aaa"}
+	require.NoError(t, k8s.Update(ctx, got))
+	got = reconcile()
+	assert.NotContains(t, got.Annotations, v1alpha1.PromotionApproveAnnotation)
+	assert.NotEmpty(t, got.Status.Stages[1].ApprovedAt)
+	assert.Equal(t, "sha256:aaa", got.Status.Stages[1].ApprovedDigest)
+	assert.Equal(t, v1alpha1.StageProgressing, got.Status.Stages[1].Phase)
+	publish("checkout-prod")
+	got = reconcile()
+	assert.Equal(t, v1alpha1.StageSucceeded, got.Status.Stages[1].Phase)
+	assert.Equal(t, "sha256:aaa", got.Status.Stages[1].Digest)
+	assert.Empty(t, got.Status.CurrentStage)
+
+	// A new digest starts over from the first stage; production keeps serving the old one
+	require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(src), src))
+	src.Status.Digest = "sha256:bbb"
+	require.NoError(t, k8s.Status().Update(ctx, src))
+	got = reconcile()
+	assert.Equal(t, "staging", got.Status.CurrentStage)
+	assert.Equal(t, v1alpha1.StagePending, got.Status.Stages[1].Phase)
+	assert.Equal(t, "sha256:aaa", got.Status.Stages[1].Digest)
+	assert.Empty(t, got.Status.Stages[1].ApprovedAt)
+	staging = publish("checkout-staging")
+	assert.Equal(t, "registry.example.com/mfe/checkout@sha256:bbb", staging.Spec.OCIArtifact)
+
+	// The approval given for the old digest does not promote the new one
+	got = reconcile()
+	assert.Equal(t, v1alpha1.StageWaitingForApproval, got.Status.Stages[1].Phase)
+	got.Annotations = map[string]string{v1alpha1.PromotionApproveAnnotation: "prod@sha256:aaa"}
+	require.NoError(t, k8s.Update(ctx, got))
+	got = reconcile()
+	assert.NotContains(t, got.Annotations, v1alpha1.PromotionApproveAnnotation)
+	assert.Equal(t, v1alpha1.StageWaitingForApproval, got.Status.Stages[1].Phase)
+	require.NoError(t, k8s.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "checkout-prod"}, &prod))
+	assert.Equal(t, "registry.example.com/mfe/checkout@sha256:aaa", prod.Spec.OCIArtifact)
+
+	// Removing a stage deletes its MicroFrontend
+	got.Spec.Stages = got.Spec.Stages[:1]
+	require.NoError(t, k8s.Update(ctx, got))
+	got = reconcile()
+	require.Len(t, got.Status.Stages, 1)
+	assert.True(t, apierrors.IsNotFound(k8s.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "checkout-prod"}, &prod)))
+	require.NoError(t, k8s.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "checkout-staging"}, staging))
+}
+
+func TestPromotionStagesKeepCatalogIdentity(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantRemote  string
+	}{
+		{name: "default remote name", wantRemote: "checkout"},
+		{name: "overridden remote name", annotations: map[string]string{v1alpha1.RemoteNameAnnotation: "checkoutApp"}, wantRemote: "checkoutApp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+			ctx := context.Background()
+			src := &v1alpha1.MicroFrontend{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "shop",
+					Name:        "checkout",
+					Labels:      map[string]string{"catalog": "public"},
+					Annotations: tt.annotations,
+				},
+				Spec:   v1alpha1.MicroFrontendSpec{OCIArtifact: "registry.example.com/mfe/checkout:v1.2.0", CDNTarget: "dev/checkout"},
+				Status: v1alpha1.MicroFrontendStatus{Synced: true, Digest: "sha256:aaa"},
+			}
+			promo := &v1alpha1.Promotion{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+				Spec: v1alpha1.PromotionSpec{
+					MicroFrontend: "checkout",
+					Stages:        []v1alpha1.PromotionStage{{Name: "staging", CDNTarget: "staging/checkout"}},
+				},
+			}
+			k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(src, promo).Build()
+			r := &controllers.PromotionReconciler{Client: k8s, Scheme: scheme, Recorder: record.NewFakeRecorder(20)}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(promo)})
+			require.NoError(t, err)
+
+			// The stage stays in the source's catalog, under its remote name
+			var list v1alpha1.MicroFrontendList
+			require.NoError(t, k8s.List(ctx, &list, client.MatchingLabels{"catalog": "public", v1alpha1.PromotionStageLabel: "staging"}))
+			require.Len(t, list.Items, 1)
+			assert.Equal(t, "checkout-staging", list.Items[0].Name)
+			assert.Equal(t, tt.wantRemote, list.Items[0].Annotations[v1alpha1.RemoteNameAnnotation])
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+	"mfe-operator/pkg/module"
+)
+
+// rolloutVersionPrefix returns where the bundle with digest is published
+// under prefix while Spec.Rollout is set, so that versions under rollout
+// do not overwrite each other.
+func rolloutVersionPrefix(prefix, digest string) string {
+	_, hex, _ := strings.Cut(digest, ":")
+	if len(hex) > 12 {
+		hex = hex[:12]
+	}
+	return path.Join(prefix, "versions", hex)
+}
+
+// versionDir matches the directory names made by rolloutVersionPrefix.
+var versionDir = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// retireVersions deletes the versions published under prefix for rollout
+// that no longer receive traffic: the previous stable version after a
+// promotion, an aborted or replaced canary, and all of them once
+// Spec.Rollout is removed. Versions left behind by a failed delete are
+// retried the next time the rollout changes. It returns how many versions
+// were deleted.
+func retireVersions(ctx context.Context, backend cdn.Backend, prefix string, mfe *v1alpha1.MicroFrontend) (int, error) {
+	live := map[string]bool{}
+	if st := mfe.Status.Rollout; st != nil {
+		live[rolloutVersionPrefix(prefix, st.Stable.Digest)] = true
+		if st.Phase == v1alpha1.RolloutProgressing && st.Canary != nil {
+			live[rolloutVersionPrefix(prefix, st.Canary.Digest)] = true
+		}
+	}
+	versions := path.Join(prefix, "versions")
+	files, err := cdn.ListFiles(ctx, backend.Client, versions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list published versions: %w", err)
+	}
+	retired := map[string][]string{}
+	for _, file := range files {
+		dir, rest, ok := strings.Cut(file, "/")
+		if !ok || !versionDir.MatchString(dir) || live[path.Join(versions, dir)] {
+			continue
+		}
+		retired[dir] = append(retired[dir], rest)
+	}
+	var deleted int
+	for dir, files := range retired {
+		if err := cdn.DeleteFiles(ctx, backend.Client, path.Join(versions, dir), files); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// retire runs retireVersions once the status no longer references the
+// retired versions. Failures are reported, not returned: the status is
+// already saved and the next rollout change retries.
+func (r *MicroFrontendReconciler) retire(ctx context.Context, mfe *v1alpha1.MicroFrontend, backend cdn.Backend, prefix string) {
+	deleted, err := retireVersions(ctx, backend, prefix, mfe)
+	if errors.Is(err, cdn.ErrListUnsupported) {
+		log.FromContext(ctx).Info("CDN backend cannot list files, retired versions are kept", "target", mfe.Spec.CDNTarget)
+		return
+	}
+	if deleted > 0 {
+		r.Recorder.Eventf(mfe, corev1.EventTypeNormal, "VersionsRetired", "Deleted %d versions that no longer receive traffic", deleted)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to delete retired versions")
+		r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "CleanupFailed", "Failed to delete retired versions: %v", err)
+	}
+}
+
+// startRollout records version as published. The first version, and one
+// identical to the stable version, is promoted at once; any other becomes
+// the canary at the first step, replacing an unfinished canary. Publishing
+// the canary again, e.g. after a spec change, keeps its progress.
+func startRollout(mfe *v1alpha1.MicroFrontend, version v1alpha1.RolloutVersion, now time.Time) {
+	steps := mfe.Spec.Rollout.Steps
+	st := mfe.Status.Rollout
+	if st != nil && st.Canary != nil && st.Canary.Digest == version.Digest {
+		st.Canary = &version
+		if st.Phase == v1alpha1.RolloutProgressing {
+			if int(st.Step) >= len(steps) {
+				st.Step = int32(len(steps) - 1)
+			}
+			st.Weight = steps[st.Step].Weight
+		}
+		return
+	}
+
+	stable := v1alpha1.RolloutVersion{Digest: mfe.Status.Digest, EntryURL: mfe.Status.EntryURL}
+	if st != nil {
+		stable = st.Stable
+	}
+	if stable.Digest == "" || stable.Digest == version.Digest {
+		mfe.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: version}
+		mfe.Status.EntryURL = version.EntryURL
+		return
+	}
+	mfe.Status.Rollout = &v1alpha1.RolloutStatus{
+		Phase:         v1alpha1.RolloutProgressing,
+		Stable:        stable,
+		Canary:        &version,
+		Weight:        steps[0].Weight,
+		StepStartedAt: now.Format(time.RFC3339),
+	}
+	mfe.Status.EntryURL = stable.EntryURL
+}
+
+// progressRollout acts on the rollout annotation, removing it, and ends
+// the current step once its Pause has elapsed. Completing the last step
+// promotes the canary. It reports whether the rollout status changed.
+func (r *MicroFrontendReconciler) progressRollout(ctx context.Context, mfe *v1alpha1.MicroFrontend, now time.Time) (bool, error) {
+	action, annotated := mfe.Annotations[v1alpha1.RolloutAnnotation]
+	if annotated {
+		// Update a copy so that pending status changes survive the update
+		obj := mfe.DeepCopy()
+		delete(obj.Annotations, v1alpha1.RolloutAnnotation)
+		if err := r.Update(ctx, obj); err != nil {
+			return false, fmt.Errorf("failed to remove rollout annotation: %w", err)
+		}
+		mfe.Annotations, mfe.ResourceVersion = obj.Annotations, obj.ResourceVersion
+	}
+
+	st := mfe.Status.Rollout
+	if mfe.Spec.Rollout == nil || st == nil || st.Phase != v1alpha1.RolloutProgressing || st.Canary == nil {
+		if annotated {
+			log.FromContext(ctx).Info("Ignoring rollout annotation, no rollout in progress", "value", action)
+		}
+		return false, nil
+	}
+
+	switch {
+	case action == v1alpha1.RolloutAbort:
+		st.Phase = v1alpha1.RolloutAborted
+		st.Weight = 0
+		r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "RolloutAborted",
+			"Aborted rollout of %s; all traffic is back on %s", st.Canary.Digest, st.Stable.Digest)
+		return true, nil
+	case action == v1alpha1.RolloutPromote:
+	case rolloutDue(mfe, now) < 0:
+	default:
+		if annotated {
+			log.FromContext(ctx).Info("Ignoring unknown rollout annotation", "value", action)
+		}
+		return false, nil
+	}
+
+	steps := mfe.Spec.Rollout.Steps
+	st.Step++
+	if int(st.Step) >= len(steps) {
+		r.Recorder.Eventf(mfe, corev1.EventTypeNormal, "RolloutPromoted", "Promoted %s to stable", st.Canary.Digest)
+		mfe.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: *st.Canary}
+		mfe.Status.EntryURL = st.Canary.EntryURL
+		return true, nil
+	}
+	st.Weight = steps[st.Step].Weight
+	st.StepStartedAt = now.Format(time.RFC3339)
+	r.Recorder.Eventf(mfe, corev1.EventTypeNormal, "RolloutStep",
+		"Step %d/%d: serving %s to %d%% of users", st.Step+1, len(steps), st.Canary.Digest, st.Weight)
+	return true, nil
+}
+
+// rolloutDue returns how long the current step of a progressing rollout
+// has left to run, negative once it is over. It returns 0 for steps that
+// wait for the rollout annotation and when no rollout is in progress.
+func rolloutDue(mfe *v1alpha1.MicroFrontend, now time.Time) time.Duration {
+	st := mfe.Status.Rollout
+	if mfe.Spec.Rollout == nil || st == nil || st.Phase != v1alpha1.RolloutProgressing {
+		return 0
+	}
+	steps := mfe.Spec.Rollout.Steps
+	if int(st.Step) >= len(steps) || steps[st.Step].Pause == nil {
+		return 0
+	}
+	started, err := time.Parse(time.RFC3339, st.StepStartedAt)
+	if err != nil {
+		return -1
+	}
+	due := started.Add(steps[st.Step].Pause.Duration).Sub(now)
+	if due == 0 {
+		return -1
+	}
+	return due
+}
+
+// rolloutTraffic returns the versions of mfe that currently receive
+// traffic and their weights, or nil when mfe is not under rollout.
+func rolloutTraffic(mfe *v1alpha1.MicroFrontend) []module.WeightedRemote {
+	st := mfe.Status.Rollout
+	if st == nil {
+		return nil
+	}
+	stable := module.WeightedRemote{URL: st.Stable.EntryURL, Digest: st.Stable.Digest}
+	var canary module.WeightedRemote
+	if st.Canary != nil {
+		canary = module.WeightedRemote{URL: st.Canary.EntryURL, Digest: st.Canary.Digest}
+	}
+	return module.SplitTraffic(stable, canary, st.Weight)
+}
+
+// liveDigest returns the digest of the version served to users by
+// default, i.e. the stable one while a rollout is in progress.
+func liveDigest(mfe *v1alpha1.MicroFrontend) string {
+	if st := mfe.Status.Rollout; st != nil && st.Stable.Digest != "" {
+		return st.Stable.Digest
+	}
+	return mfe.Status.Digest
+}
+
+// publishRollout writes the rollout manifest of mfe under prefix.
+func publishRollout(ctx context.Context, backend cdn.Backend, prefix string, mfe *v1alpha1.MicroFrontend) error {
+	data, err := json.MarshalIndent(module.RolloutManifest{Versions: rolloutTraffic(mfe)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rollout manifest: %w", err)
+	}
+	if err := cdn.UploadBytes(ctx, backend.Client, data, path.Join(prefix, module.RolloutManifestFile)); err != nil {
+		return fmt.Errorf("failed to publish rollout manifest: %w", err)
+	}
+	return nil
+}
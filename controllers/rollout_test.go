@@ -0,0 +1,160 @@
+package controllers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+func TestRollout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stable := v1alpha1.RolloutVersion{Digest: "sha256:aaa", EntryURL: "https://cdn.example.com/checkout/remoteEntry.js"}
+	canary := v1alpha1.RolloutVersion{Digest: "sha256:bbb", EntryURL: "https://cdn.example.com/checkout/versions/bbb/remoteEntry.js"}
+
+	newMFE := func() *v1alpha1.MicroFrontend {
+		return &v1alpha1.MicroFrontend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+			Spec: v1alpha1.MicroFrontendSpec{
+				CDNTarget: "primary/checkout",
+				Rollout: &v1alpha1.RolloutSpec{Steps: []v1alpha1.RolloutStep{
+					{Weight: 10, Pause: &metav1.Duration{Duration: time.Hour}},
+					{Weight: 50},
+				}},
+			},
+			Status: v1alpha1.MicroFrontendStatus{Synced: true, Digest: stable.Digest, EntryURL: stable.EntryURL},
+		}
+	}
+
+	t.Run("promote", func(t *testing.T) {
+		mfe := newMFE()
+		k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mfe).Build()
+		r := &controllers.MicroFrontendReconciler{Client: k8s, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+
+		// The previous version stays stable while the new one takes the first step
+		controllers.StartRollout(mfe, canary, start)
+		require.NotNil(t, mfe.Status.Rollout)
+		assert.Equal(t, v1alpha1.RolloutProgressing, mfe.Status.Rollout.Phase)
+		assert.Equal(t, stable, mfe.Status.Rollout.Stable)
+		assert.Equal(t, int32(10), mfe.Status.Rollout.Weight)
+		assert.Equal(t, stable.EntryURL, mfe.Status.EntryURL)
+
+		advanced, err := controllers.ProgressRollout(r, ctx, mfe, start.Add(30*time.Minute))
+		require.NoError(t, err)
+		assert.False(t, advanced)
+
+		// The timed step ends after its pause; the next one waits for the annotation
+		advanced, err = controllers.ProgressRollout(r, ctx, mfe, start.Add(time.Hour))
+		require.NoError(t, err)
+		assert.True(t, advanced)
+		assert.Equal(t, int32(1), mfe.Status.Rollout.Step)
+		assert.Equal(t, int32(50), mfe.Status.Rollout.Weight)
+
+		advanced, err = controllers.ProgressRollout(r, ctx, mfe, start.Add(48*time.Hour))
+		require.NoError(t, err)
+		assert.False(t, advanced)
+
+		mfe.Annotations = map[string]string{v1alpha1.RolloutAnnotation: v1alpha1.RolloutPromote}
+		advanced, err = controllers.ProgressRollout(r, ctx, mfe, start.Add(49*time.Hour))
+		require.NoError(t, err)
+		assert.True(t, advanced)
+		assert.Equal(t, &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: canary}, mfe.Status.Rollout)
+		assert.Equal(t, canary.EntryURL, mfe.Status.EntryURL)
+
+		var stored v1alpha1.MicroFrontend
+		require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(mfe), &stored))
+		assert.NotContains(t, stored.Annotations, v1alpha1.RolloutAnnotation)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		mfe := newMFE()
+		mfe.Annotations = map[string]string{v1alpha1.RolloutAnnotation: v1alpha1.RolloutAbort}
+		k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mfe).Build()
+		r := &controllers.MicroFrontendReconciler{Client: k8s, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+
+		controllers.StartRollout(mfe, canary, start)
+		advanced, err := controllers.ProgressRollout(r, ctx, mfe, start)
+		require.NoError(t, err)
+		assert.True(t, advanced)
+		assert.Equal(t, v1alpha1.RolloutAborted, mfe.Status.Rollout.Phase)
+		assert.Equal(t, int32(0), mfe.Status.Rollout.Weight)
+		assert.Equal(t, stable.EntryURL, mfe.Status.EntryURL)
+
+		// Publishing the aborted version again does not restart its rollout
+		controllers.StartRollout(mfe, canary, start.Add(time.Hour))
+		assert.Equal(t, v1alpha1.RolloutAborted, mfe.Status.Rollout.Phase)
+		assert.Equal(t, int32(0), mfe.Status.Rollout.Weight)
+	})
+
+	t.Run("first version", func(t *testing.T) {
+		mfe := newMFE()
+		mfe.Status = v1alpha1.MicroFrontendStatus{}
+		controllers.StartRollout(mfe, canary, start)
+		assert.Equal(t, &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: canary}, mfe.Status.Rollout)
+		assert.Equal(t, canary.EntryURL, mfe.Status.EntryURL)
+	})
+}
+
+func TestRetireVersions(t *testing.T) {
+	ctx := context.Background()
+	files, err := cdn.NewFileUploader(t.TempDir())
+	require.NoError(t, err)
+	backend := cdn.Backend{Client: files}
+	bundle := reactBundle(t, "chunk")
+	for _, prefix := range []string{"checkout", "checkout/versions/aaaaaaaaaaaa", "checkout/versions/bbbbbbbbbbbb", "checkout/versions/cccccccccccc"} {
+		require.NoError(t, cdn.UploadDirectoryToCDN(ctx, files, bundle, prefix))
+	}
+	published := func(prefix string) bool {
+		listed, err := cdn.ListFiles(ctx, files, prefix)
+		require.NoError(t, err)
+		return len(listed) > 0
+	}
+
+	mfe := &v1alpha1.MicroFrontend{Status: v1alpha1.MicroFrontendStatus{Rollout: &v1alpha1.RolloutStatus{
+		Phase:  v1alpha1.RolloutProgressing,
+		Stable: v1alpha1.RolloutVersion{Digest: "sha256:aaaaaaaaaaaa0000"},
+		Canary: &v1alpha1.RolloutVersion{Digest: "sha256:bbbbbbbbbbbb0000"},
+		Weight: 10,
+	}}}
+
+	// A replaced canary is deleted; the stable version and the canary stay
+	deleted, err := controllers.RetireVersions(ctx, backend, "checkout", mfe)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.True(t, published("checkout/versions/aaaaaaaaaaaa"))
+	assert.True(t, published("checkout/versions/bbbbbbbbbbbb"))
+	assert.False(t, published("checkout/versions/cccccccccccc"))
+
+	// An aborted canary is deleted
+	mfe.Status.Rollout.Phase, mfe.Status.Rollout.Weight = v1alpha1.RolloutAborted, 0
+	deleted, err = controllers.RetireVersions(ctx, backend, "checkout", mfe)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.False(t, published("checkout/versions/bbbbbbbbbbbb"))
+
+	// After a promotion the previous stable version is deleted
+	require.NoError(t, cdn.UploadDirectoryToCDN(ctx, files, bundle, "checkout/versions/bbbbbbbbbbbb"))
+	mfe.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: *mfe.Status.Rollout.Canary}
+	deleted, err = controllers.RetireVersions(ctx, backend, "checkout", mfe)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.False(t, published("checkout/versions/aaaaaaaaaaaa"))
+	assert.True(t, published("checkout/versions/bbbbbbbbbbbb"))
+
+	// Files outside versions/ are never touched
+	assert.True(t, published("checkout/js"))
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+	"mfe-operator/pkg/module"
+)
+
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=sharedmoduleversions,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=sharedmoduleversions/status,verbs=get;update;patch
+
+// nonNameChars matches runs of characters not allowed in object names.
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// publishSharedModules uploads the chunk files of the bundle's shared
+// dependencies under vendor/ on the target backend and records every shared
+// dependency in the status. Versions already published with identical
+// content are reused, see registerSharedModule.
+func (r *MicroFrontendReconciler) publishSharedModules(ctx context.Context, mfe *v1alpha1.MicroFrontend, backend cdn.Backend, bundlePath, entry string) error {
+	modules, err := module.AnalyzeSharedModules(bundlePath, entry)
+	if err != nil {
+		return fmt.Errorf("failed to analyze shared modules: %w", err)
+	}
+	backendName, _, err := cdn.SplitTarget(mfe.Spec.CDNTarget)
+	if err != nil {
+		return permanent(err)
+	}
+
+	var published []v1alpha1.SharedModuleStatus
+	shipped := map[string]bool{}
+	for _, m := range modules {
+		if len(m.Files) == 0 {
+			log.FromContext(ctx).Info("Skipping shared module without chunk files", "module", m.Name+"@"+m.Version)
+			published = append(published, sharedModuleStatus(m))
+			continue
+		}
+		shipped[sharedModuleVersionName(backendName, m.Name, m.Version)] = true
+		status, err := r.registerSharedModule(ctx, mfe, backend, backendName, bundlePath, m)
+		if err != nil {
+			return err
+		}
+		published = append(published, status)
+	}
+
+	// Drop references to versions the bundle no longer ships, unless the
+	// stable version of a rollout still loads them
+	stable := map[string]bool{}
+	if st := mfe.Status.Rollout; st != nil {
+		for _, p := range st.Stable.SharedModules {
+			stable[p] = true
+		}
+	}
+	for _, m := range mfe.Status.SharedModules {
+		if m.Path == "" || stable[m.Path] {
+			continue
+		}
+		name := sharedModuleVersionName(backendName, m.Name, m.Version)
+		if !shipped[name] {
+			if err := r.releaseSharedModule(ctx, mfe, name); err != nil {
+				return err
+			}
+		}
+	}
+	mfe.Status.SharedModules = published
+	return nil
+}
+
+// registerSharedModule publishes m and records mfe as a reference on its
+// SharedModuleVersion. The first MicroFrontend to ship a version claims it
+// for its content hash, see claimSharedModule, and only the claimant
+// uploads; later copies with the same hash reuse the files. If the version
+// was claimed for different content, mfe's copy is not uploaded; the
+// collision is recorded on the SharedModuleVersion, a warning event is
+// emitted and the returned status has no Path.
+func (r *MicroFrontendReconciler) registerSharedModule(ctx context.Context, mfe *v1alpha1.MicroFrontend, backend cdn.Backend, backendName, bundlePath string, m module.SharedModule) (v1alpha1.SharedModuleStatus, error) {
+	logger := log.FromContext(ctx)
+	status := sharedModuleStatus(m)
+	hash, err := module.ContentHash(bundlePath, m)
+	if err != nil {
+		return status, fmt.Errorf("failed to hash shared module %s@%s: %w", m.Name, m.Version, err)
+	}
+	status.ContentHash = hash
+
+	name := sharedModuleVersionName(backendName, m.Name, m.Version)
+	smv, err := r.claimSharedModule(ctx, name, backendName, m, hash)
+	if err != nil {
+		return status, err
+	}
+	ref := v1alpha1.SharedModuleReference{Namespace: mfe.Namespace, Name: mfe.Name}
+	if smv.Status.ContentHash != hash {
+		err := r.updateSharedModuleStatus(ctx, name, func(st *v1alpha1.SharedModuleVersionStatus) {
+			st.References = removeReference(st.References, ref)
+			st.Collisions = append(removeCollision(st.Collisions, ref), v1alpha1.SharedModuleCollision{
+				SharedModuleReference: ref,
+				ContentHash:           hash,
+				DetectedAt:            time.Now().Format(time.RFC3339),
+			})
+		})
+		if err != nil {
+			return status, err
+		}
+		r.Recorder.Eventf(mfe, corev1.EventTypeWarning, "SharedModuleCollision",
+			"%s@%s has content %s but %s is published under %s; not publishing this copy",
+			m.Name, m.Version, hash, smv.Status.ContentHash, smv.Spec.Path)
+		return status, nil
+	}
+
+	publishedAt := smv.Status.PublishedAt
+	if publishedAt == "" {
+		// Claimed for this content, but the upload has not completed yet.
+		// Uploading the same content again is harmless.
+		if _, err := module.UploadSharedModules(ctx, backend.Client, bundlePath, []module.SharedModule{m}); err != nil {
+			return status, fmt.Errorf("failed to upload shared modules: %w", err)
+		}
+		publishedAt = time.Now().Format(time.RFC3339)
+	} else {
+		logger.Info("Shared module already published", "module", m.Name+"@"+m.Version, "contentHash", hash)
+	}
+	err = r.updateSharedModuleStatus(ctx, name, func(st *v1alpha1.SharedModuleVersionStatus) {
+		if st.PublishedAt == "" {
+			st.PublishedAt = publishedAt
+		}
+		st.Collisions = removeCollision(st.Collisions, ref)
+		st.UnreferencedSince = ""
+		if !hasReference(st.References, ref) {
+			st.References = append(st.References, ref)
+		}
+	})
+	if err != nil {
+		return status, err
+	}
+
+	status.Path = module.VendorPath(m)
+	status.Files = m.Files
+	return status, nil
+}
+
+// claimSharedModule returns the named SharedModuleVersion for m, creating
+// it if needed. A version no one has claimed yet is claimed for hash before
+// anything is uploaded: the status update fails on a resourceVersion
+// conflict if another MicroFrontend claimed it first, in which case the
+// claim is read again, so the files on the CDN always have the recorded
+// content hash.
+func (r *MicroFrontendReconciler) claimSharedModule(ctx context.Context, name, backendName string, m module.SharedModule, hash string) (*v1alpha1.SharedModuleVersion, error) {
+	var smv v1alpha1.SharedModuleVersion
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, &smv); apierrors.IsNotFound(err) {
+			smv = v1alpha1.SharedModuleVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Finalizers: []string{vendorGCFinalizer}},
+				Spec: v1alpha1.SharedModuleVersionSpec{
+					Backend: backendName,
+					Package: m.Name,
+					Version: m.Version,
+					Path:    module.VendorPath(m),
+				},
+			}
+			if err := r.Create(ctx, &smv); apierrors.IsAlreadyExists(err) {
+				// Created concurrently; read it again
+				return apierrors.NewConflict(v1alpha1.GroupVersion.WithResource("sharedmoduleversions").GroupResource(), name, err)
+			} else if err != nil {
+				return fmt.Errorf("failed to create SharedModuleVersion %s: %w", name, err)
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to get SharedModuleVersion %s: %w", name, err)
+		} else if !smv.DeletionTimestamp.IsZero() {
+			// Retry once the collector has removed the files and the object
+			return fmt.Errorf("SharedModuleVersion %s is being garbage collected", name)
+		}
+		if smv.Status.ContentHash != "" {
+			return nil
+		}
+		smv.Status.ContentHash = hash
+		smv.Status.Files = m.Files
+		return r.Status().Update(ctx, &smv)
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, fmt.Errorf("failed to claim SharedModuleVersion %s: %w", name, err)
+		}
+		return nil, err
+	}
+	return &smv, nil
+}
+
+// updateSharedModuleStatus applies update to the status of the named
+// SharedModuleVersion, reading it again whenever another MicroFrontend
+// updated it concurrently.
+func (r *MicroFrontendReconciler) updateSharedModuleStatus(ctx context.Context, name string, update func(*v1alpha1.SharedModuleVersionStatus)) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var smv v1alpha1.SharedModuleVersion
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, &smv); err != nil {
+			return err
+		}
+		update(&smv.Status)
+		return r.Status().Update(ctx, &smv)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update SharedModuleVersion %s: %w", name, err)
+	}
+	return nil
+}
+
+// releaseSharedModule removes mfe from the references and collisions of the
+// named SharedModuleVersion.
+func (r *MicroFrontendReconciler) releaseSharedModule(ctx context.Context, mfe *v1alpha1.MicroFrontend, name string) error {
+	var smv v1alpha1.SharedModuleVersion
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, &smv); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	ref := v1alpha1.SharedModuleReference{Namespace: mfe.Namespace, Name: mfe.Name}
+	refs, collisions := len(smv.Status.References), len(smv.Status.Collisions)
+	smv.Status.References = removeReference(smv.Status.References, ref)
+	smv.Status.Collisions = removeCollision(smv.Status.Collisions, ref)
+	if len(smv.Status.References) == refs && len(smv.Status.Collisions) == collisions {
+		return nil
+	}
+	if err := r.Status().Update(ctx, &smv); err != nil {
+		return fmt.Errorf("failed to update SharedModuleVersion %s: %w", name, err)
+	}
+	return nil
+}
+
+// sharedModuleVersionName returns the name of the SharedModuleVersion for a
+// package version on a backend, e.g. "mui-material-5-15-0-1a2b3c4d". The
+// suffix keeps names unique where sanitizing maps several inputs together.
+func sharedModuleVersionName(backend, pkg, version string) string {
+	sum := sha256.Sum256([]byte(backend + "/" + pkg + "@" + version))
+	base := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(pkg+"-"+version), "-"), "-")
+	if len(base) > 200 {
+		base = strings.TrimRight(base[:200], "-")
+	}
+	return fmt.Sprintf("%s-%x", base, sum[:4])
+}
+
+func hasReference(refs []v1alpha1.SharedModuleReference, ref v1alpha1.SharedModuleReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func removeReference(refs []v1alpha1.SharedModuleReference, ref v1alpha1.SharedModuleReference) []v1alpha1.SharedModuleReference {
+	var kept []v1alpha1.SharedModuleReference
+	for _, r := range refs {
+		if r != ref {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+func removeCollision(collisions []v1alpha1.SharedModuleCollision, ref v1alpha1.SharedModuleReference) []v1alpha1.SharedModuleCollision {
+	var kept []v1alpha1.SharedModuleCollision
+	for _, c := range collisions {
+		if c.SharedModuleReference != ref {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func sharedModuleStatus(m module.SharedModule) v1alpha1.SharedModuleStatus {
+	return v1alpha1.SharedModuleStatus{
+		Name:            m.Name,
+		Version:         m.Version,
+		RequiredVersion: m.RequiredVersion,
+		Singleton:       m.Singleton,
+	}
+}
+
+// vendorPaths returns the vendor/ paths modules were published under.
+func vendorPaths(modules []v1alpha1.SharedModuleStatus) []string {
+	var paths []string
+	for _, m := range modules {
+		if m.Path != "" {
+			paths = append(paths, m.Path)
+		}
+	}
+	return paths
+}
+
+// sharedModuleNames lists name@version of each module for messages.
+func sharedModuleNames(modules []v1alpha1.SharedModuleStatus) string {
+	names := make([]string, 0, len(modules))
+	for _, m := range modules {
+		names = append(names, m.Name+"@"+m.Version)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,180 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// recordingCDN records the remote paths uploaded to it.
+type recordingCDN struct {
+	uploads []string
+}
+
+func (c *recordingCDN) Upload(ctx context.Context, localPath, remotePath string) error {
+	c.uploads = append(c.uploads, remotePath)
+	return nil
+}
+
+func writeSharedBundle(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+	}
+	return dir
+}
+
+func reactBundle(t *testing.T, chunk string) string {
+	return writeSharedBundle(t, map[string]string{
+		"remoteEntry.js": "",
+		"mf-manifest.json": `{"shared": [{"name": "react", "version": "18.2.0", "singleton": true,
+			"assets": {"js": {"sync": [], "async": ["js/935.e1f2.js"]}}}]}`,
+		"js/935.e1f2.js": chunk,
+	})
+}
+
+func TestPublishSharedModulesDeduplicates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	k8s := fake.NewClientBuilder().WithScheme(scheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &controllers.MicroFrontendReconciler{Client: k8s, Scheme: scheme, Recorder: recorder}
+	uploader := &recordingCDN{}
+	backend := cdn.Backend{Client: uploader}
+	ctx := context.Background()
+
+	newMFE := func(name string) *v1alpha1.MicroFrontend {
+		return &v1alpha1.MicroFrontend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec:       v1alpha1.MicroFrontendSpec{CDNTarget: "primary/apps/" + name},
+		}
+	}
+	registry := func() v1alpha1.SharedModuleVersion {
+		var list v1alpha1.SharedModuleVersionList
+		require.NoError(t, k8s.List(ctx, &list))
+		require.Len(t, list.Items, 1)
+		return list.Items[0]
+	}
+
+	// The first MicroFrontend publishes react@18.2.0
+	a := newMFE("checkout")
+	require.NoError(t, controllers.PublishSharedModules(r, ctx, a, backend, reactBundle(t, "react"), "remoteEntry.js"))
+	assert.Equal(t, []string{"vendor/react@18.2.0/js/935.e1f2.js"}, uploader.uploads)
+	require.Len(t, a.Status.SharedModules, 1)
+	smv := registry()
+	assert.Equal(t, v1alpha1.SharedModuleVersionSpec{Backend: "primary", Package: "react", Version: "18.2.0", Path: "vendor/react@18.2.0"}, smv.Spec)
+	assert.Equal(t, a.Status.SharedModules[0].ContentHash, smv.Status.ContentHash)
+	assert.Equal(t, []string{"js/935.e1f2.js"}, smv.Status.Files)
+
+	// An identical copy is not uploaded again
+	b := newMFE("catalog")
+	require.NoError(t, controllers.PublishSharedModules(r, ctx, b, backend, reactBundle(t, "react"), "remoteEntry.js"))
+	assert.Len(t, uploader.uploads, 1)
+	assert.Equal(t, a.Status.SharedModules, b.Status.SharedModules)
+	assert.Equal(t, []v1alpha1.SharedModuleReference{{Namespace: "shop", Name: "checkout"}, {Namespace: "shop", Name: "catalog"}}, registry().Status.References)
+
+	// Different content under the same version is flagged, not published
+	c := newMFE("search")
+	require.NoError(t, controllers.PublishSharedModules(r, ctx, c, backend, reactBundle(t, "react (patched)"), "remoteEntry.js"))
+	assert.Len(t, uploader.uploads, 1)
+	require.Len(t, c.Status.SharedModules, 1)
+	assert.Empty(t, c.Status.SharedModules[0].Path)
+	smv = registry()
+	require.Len(t, smv.Status.Collisions, 1)
+	assert.Equal(t, "search", smv.Status.Collisions[0].Name)
+	assert.NotEqual(t, smv.Status.ContentHash, smv.Status.Collisions[0].ContentHash)
+	assert.Contains(t, <-recorder.Events, "SharedModuleCollision")
+
+	// The stable version of a rollout keeps its reference
+	b.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: v1alpha1.RolloutVersion{SharedModules: []string{"vendor/react@18.2.0"}}}
+	require.NoError(t, controllers.PublishSharedModules(r, ctx, b, backend, writeSharedBundle(t, map[string]string{"remoteEntry.js": ""}), "remoteEntry.js"))
+	assert.Empty(t, b.Status.SharedModules)
+	assert.Equal(t, []v1alpha1.SharedModuleReference{{Namespace: "shop", Name: "checkout"}, {Namespace: "shop", Name: "catalog"}}, registry().Status.References)
+
+	// Dropping the dependency releases the reference
+	require.NoError(t, controllers.PublishSharedModules(r, ctx, a, backend, writeSharedBundle(t, map[string]string{"remoteEntry.js": ""}), "remoteEntry.js"))
+	assert.Empty(t, a.Status.SharedModules)
+	assert.Equal(t, []v1alpha1.SharedModuleReference{{Namespace: "shop", Name: "catalog"}}, registry().Status.References)
+}
+
+// lockedCDN holds uploaded files by remote path and is safe for concurrent
+// use.
+type lockedCDN struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (c *lockedCDN) Upload(ctx context.Context, localPath, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[remotePath] = data
+	return nil
+}
+
+func TestPublishSharedModulesConcurrently(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	k8s := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &controllers.MicroFrontendReconciler{Client: k8s, Scheme: scheme, Recorder: record.NewFakeRecorder(100)}
+	uploader := &lockedCDN{files: map[string][]byte{}}
+	backend := cdn.Backend{Client: uploader}
+	ctx := context.Background()
+
+	// MicroFrontends ship react@18.2.0 with two different contents at once
+	chunks := []string{"react", "react (patched)"}
+	mfes := make([]*v1alpha1.MicroFrontend, 8)
+	var wg sync.WaitGroup
+	for i := range mfes {
+		mfes[i] = &v1alpha1.MicroFrontend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: fmt.Sprintf("app-%d", i)},
+			Spec:       v1alpha1.MicroFrontendSpec{CDNTarget: fmt.Sprintf("primary/apps/app-%d", i)},
+		}
+		bundle := reactBundle(t, chunks[i%2])
+		wg.Add(1)
+		go func(mfe *v1alpha1.MicroFrontend) {
+			defer wg.Done()
+			assert.NoError(t, controllers.PublishSharedModules(r, ctx, mfe, backend, bundle, "remoteEntry.js"))
+		}(mfes[i])
+	}
+	wg.Wait()
+
+	// One content wins; the CDN holds exactly that content
+	var list v1alpha1.SharedModuleVersionList
+	require.NoError(t, k8s.List(ctx, &list))
+	require.Len(t, list.Items, 1)
+	smv := list.Items[0]
+	var winner string
+	for i, mfe := range mfes {
+		require.Len(t, mfe.Status.SharedModules, 1)
+		if mfe.Status.SharedModules[0].ContentHash == smv.Status.ContentHash {
+			assert.Equal(t, "vendor/react@18.2.0", mfe.Status.SharedModules[0].Path)
+			winner = chunks[i%2]
+		} else {
+			assert.Empty(t, mfe.Status.SharedModules[0].Path)
+		}
+	}
+	assert.Equal(t, winner, string(uploader.files["vendor/react@18.2.0/js/935.e1f2.js"]))
+	assert.Len(t, smv.Status.References, 4)
+	assert.Len(t, smv.Status.Collisions, 4)
+	assert.NotEmpty(t, smv.Status.PublishedAt)
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/module"
+)
+
+// maxFragmented caps the modules listed in the report's Fragmented field.
+const maxFragmented = 20
+
+var (
+	sharedModuleVersions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mfe_shared_module_versions",
+		Help: "Number of distinct versions of a shared module in use across MicroFrontends.",
+	}, []string{"module"})
+	sharedModuleMajorVersions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mfe_shared_module_major_versions",
+		Help: "Number of incompatible release lines of a shared module in use across MicroFrontends.",
+	}, []string{"module", "singleton"})
+	sharedModuleConsumers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mfe_shared_module_consumers",
+		Help: "Number of MicroFrontends using a shared module version.",
+	}, []string{"module", "version"})
+	sharedModuleSingletonConflicts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "mfe_shared_module_singleton_conflicts",
+		Help: "Number of singleton shared modules in use at incompatible major versions.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(sharedModuleVersions, sharedModuleMajorVersions, sharedModuleConsumers, sharedModuleSingletonConflicts)
+}
+
+// SharedModuleReportReconciler compares the shared modules of all
+// MicroFrontends and publishes the version skew as the "cluster"
+// SharedModuleReport and as metrics.
+type SharedModuleReportReconciler struct {
+	client.Client
+}
+
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=sharedmodulereports,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=sharedmodulereports/status,verbs=get;update;patch
+
+func (r *SharedModuleReportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var list v1alpha1.MicroFrontendList
+	if err := r.List(ctx, &list); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list MicroFrontends: %w", err)
+	}
+	consumers := make([]module.Consumer, 0, len(list.Items))
+	for _, mfe := range list.Items {
+		c := module.Consumer{Name: mfe.Namespace + "/" + mfe.Name}
+		for _, m := range mfe.Status.SharedModules {
+			c.Modules = append(c.Modules, module.SharedModule{Name: m.Name, Version: m.Version, RequiredVersion: m.RequiredVersion, Singleton: m.Singleton})
+		}
+		consumers = append(consumers, c)
+	}
+
+	skew := module.AnalyzeSkew(consumers)
+	recordSkewMetrics(skew)
+
+	var report v1alpha1.SharedModuleReport
+	if err := r.Get(ctx, client.ObjectKey{Name: v1alpha1.SharedModuleReportName}, &report); apierrors.IsNotFound(err) {
+		report = v1alpha1.SharedModuleReport{ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.SharedModuleReportName}}
+		if err := r.Create(ctx, &report); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create SharedModuleReport: %w", err)
+		}
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get SharedModuleReport: %w", err)
+	}
+
+	status := skewReportStatus(skew, len(list.Items))
+	status.LastAnalyzedAt = report.Status.LastAnalyzedAt
+	if report.Status.LastAnalyzedAt != "" && equality.Semantic.DeepEqual(status, report.Status) {
+		return ctrl.Result{}, nil
+	}
+	status.LastAnalyzedAt = time.Now().Format(time.RFC3339)
+	report.Status = status
+	if err := r.Status().Update(ctx, &report); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update SharedModuleReport: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// skewReportStatus converts the analysis into the report status.
+func skewReportStatus(skew []module.ModuleSkew, microFrontends int) v1alpha1.SharedModuleReportStatus {
+	status := v1alpha1.SharedModuleReportStatus{MicroFrontends: microFrontends}
+	for _, s := range skew {
+		if len(s.Versions) < 2 {
+			continue
+		}
+		entry := v1alpha1.SharedModuleSkew{Name: s.Name, Singleton: s.Singleton, MajorVersions: s.Lines}
+		for _, v := range s.Versions {
+			entry.Versions = append(entry.Versions, v1alpha1.SharedModuleVersionUse{Version: v.Version, MicroFrontends: v.Consumers})
+		}
+		if s.Conflict() {
+			status.SingletonConflicts = append(status.SingletonConflicts, entry)
+		}
+		if len(status.Fragmented) < maxFragmented {
+			status.Fragmented = append(status.Fragmented, entry)
+		}
+	}
+	return status
+}
+
+func recordSkewMetrics(skew []module.ModuleSkew) {
+	sharedModuleVersions.Reset()
+	sharedModuleMajorVersions.Reset()
+	sharedModuleConsumers.Reset()
+	conflicts := 0
+	for _, s := range skew {
+		sharedModuleVersions.WithLabelValues(s.Name).Set(float64(len(s.Versions)))
+		sharedModuleMajorVersions.WithLabelValues(s.Name, fmt.Sprint(s.Singleton)).Set(float64(s.Lines))
+		for _, v := range s.Versions {
+			sharedModuleConsumers.WithLabelValues(s.Name, v.Version).Set(float64(len(v.Consumers)))
+		}
+		if s.Conflict() {
+			conflicts++
+		}
+	}
+	sharedModuleSingletonConflicts.Set(float64(conflicts))
+}
+
+// SetupWithManager re-runs the analysis whenever a MicroFrontend changes and
+// recreates the report if it is deleted.
+func (r *SharedModuleReportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	report := func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: v1alpha1.SharedModuleReportName}}}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sharedmodulereport").
+		Watches(&source.Kind{Type: &v1alpha1.MicroFrontend{}}, handler.EnqueueRequestsFromMapFunc(report)).
+		Watches(&source.Kind{Type: &v1alpha1.SharedModuleReport{}}, handler.EnqueueRequestsFromMapFunc(report),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(event.CreateEvent) bool { return false },
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+			})).
+		Complete(r)
+}
@@ -0,0 +1,68 @@
+package controllers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+)
+
+func mfeWithShared(name string, modules ...v1alpha1.SharedModuleStatus) *v1alpha1.MicroFrontend {
+	return &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+		Status:     v1alpha1.MicroFrontendStatus{SharedModules: modules},
+	}
+}
+
+func TestSharedModuleReport(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		mfeWithShared("checkout",
+			v1alpha1.SharedModuleStatus{Name: "react", Version: "18.2.0", Singleton: true},
+			v1alpha1.SharedModuleStatus{Name: "lodash", Version: "4.17.21"}),
+		mfeWithShared("catalog",
+			v1alpha1.SharedModuleStatus{Name: "react", Version: "17.0.2", Singleton: true},
+			v1alpha1.SharedModuleStatus{Name: "lodash", Version: "4.17.20"}),
+		mfeWithShared("search",
+			v1alpha1.SharedModuleStatus{Name: "react", Version: "18.2.0", Singleton: true}),
+	).Build()
+	r := &controllers.SharedModuleReportReconciler{Client: k8s}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{})
+	require.NoError(t, err)
+
+	var report v1alpha1.SharedModuleReport
+	require.NoError(t, k8s.Get(context.Background(), client.ObjectKey{Name: v1alpha1.SharedModuleReportName}, &report))
+	assert.Equal(t, 3, report.Status.MicroFrontends)
+	assert.NotEmpty(t, report.Status.LastAnalyzedAt)
+	react := v1alpha1.SharedModuleSkew{
+		Name:          "react",
+		Singleton:     true,
+		MajorVersions: 2,
+		Versions: []v1alpha1.SharedModuleVersionUse{
+			{Version: "17.0.2", MicroFrontends: []string{"shop/catalog"}},
+			{Version: "18.2.0", MicroFrontends: []string{"shop/checkout", "shop/search"}},
+		},
+	}
+	assert.Equal(t, []v1alpha1.SharedModuleSkew{react}, report.Status.SingletonConflicts)
+	require.Len(t, report.Status.Fragmented, 2)
+	assert.Equal(t, react, report.Status.Fragmented[0])
+	assert.Equal(t, "lodash", report.Status.Fragmented[1].Name)
+
+	// Unchanged findings are not written again
+	version := report.ResourceVersion
+	_, err = r.Reconcile(context.Background(), ctrl.Request{})
+	require.NoError(t, err)
+	require.NoError(t, k8s.Get(context.Background(), client.ObjectKey{Name: v1alpha1.SharedModuleReportName}, &report))
+	assert.Equal(t, version, report.ResourceVersion)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// defaultSmokeCheckChunks is used when Spec.SmokeCheck.Chunks is unset.
+const defaultSmokeCheckChunks = 3
+
+// smokeCheckRetry is the delay before a failed smoke check is repeated;
+// CDNs often take a moment to serve new files everywhere.
+const smokeCheckRetry = 30 * time.Second
+
+// defaultSmokeCheckClient is used when MicroFrontendReconciler.HTTPClient
+// is unset.
+var defaultSmokeCheckClient = &http.Client{Timeout: 10 * time.Second}
+
+// planSmokeCheck records in the status which files of the bundle at
+// bundlePath, published under uploadPrefix, the smoke check fetches.
+func planSmokeCheck(mfe *v1alpha1.MicroFrontend, backend cdn.Backend, bundlePath, entry, uploadPrefix, digest string) error {
+	baseURL, chunks := backend.PublicURL, defaultSmokeCheckChunks
+	if c := mfe.Spec.SmokeCheck; c != nil {
+		if c.BaseURL != "" {
+			baseURL = c.BaseURL
+		}
+		if c.Chunks != nil {
+			chunks = int(*c.Chunks)
+		}
+	}
+	files, err := cdn.SampleFiles(bundlePath, entry, uploadPrefix, chunks)
+	if err != nil {
+		return err
+	}
+	status := &v1alpha1.SmokeCheckStatus{Digest: digest, BaseURL: baseURL}
+	for _, f := range files {
+		status.Files = append(status.Files, v1alpha1.SmokeCheckFile{Path: f.Path, SHA256: f.SHA256})
+	}
+	mfe.Status.SmokeCheck = status
+	return nil
+}
+
+// smokeCheck fetches the files listed in Status.SmokeCheck from the CDN
+// and sets the Ready condition from the outcome. Without a base URL the
+// files cannot be fetched, so readiness is reported Unknown.
+func (r *MicroFrontendReconciler) smokeCheck(ctx context.Context, mfe *v1alpha1.MicroFrontend) bool {
+	sc := mfe.Status.SmokeCheck
+	if sc.BaseURL == "" {
+		setReady(mfe, metav1.ConditionUnknown, "SmokeCheckNotConfigured",
+			"No public URL to check the published files against; set spec.smokeCheck.baseURL or the backend's public URL")
+		return false
+	}
+	client := r.HTTPClient
+	if client == nil {
+		client = defaultSmokeCheckClient
+	}
+	files := make([]cdn.SmokeFile, 0, len(sc.Files))
+	for _, f := range sc.Files {
+		files = append(files, cdn.SmokeFile{Path: f.Path, SHA256: f.SHA256})
+	}
+	err := cdn.SmokeCheck(ctx, client, sc.BaseURL, files)
+	sc.LastCheckedAt = time.Now().Format(time.RFC3339)
+	if err != nil {
+		log.FromContext(ctx).Info("Smoke check failed", "error", err.Error())
+		r.Recorder.Event(mfe, corev1.EventTypeWarning, "SmokeCheckFailed", err.Error())
+		setReady(mfe, metav1.ConditionFalse, "SmokeCheckFailed", err.Error())
+		return false
+	}
+	msg := fmt.Sprintf("Fetched %d published files from %s", len(files), sc.BaseURL)
+	r.Recorder.Event(mfe, corev1.EventTypeNormal, "SmokeCheckPassed", msg)
+	setReady(mfe, metav1.ConditionTrue, "SmokeCheckPassed", msg)
+	return true
+}
+
+// smokeCheckPending reports whether the bundle last published has yet to
+// pass its smoke check. A check without a base URL is pending but cannot be
+// retried, see smokeCheckRetryable.
+func smokeCheckPending(mfe *v1alpha1.MicroFrontend) bool {
+	sc := mfe.Status.SmokeCheck
+	if sc == nil || !mfe.Status.Synced || sc.Digest != mfe.Status.Digest {
+		return false
+	}
+	ready := meta.FindStatusCondition(mfe.Status.Conditions, v1alpha1.ConditionReady)
+	return ready == nil || ready.Status != metav1.ConditionTrue || ready.ObservedGeneration != mfe.Generation
+}
+
+// smokeCheckRetryable reports whether a pending smoke check can be repeated.
+func smokeCheckRetryable(mfe *v1alpha1.MicroFrontend) bool {
+	return smokeCheckPending(mfe) && mfe.Status.SmokeCheck.BaseURL != ""
+}
+
+func setReady(mfe *v1alpha1.MicroFrontend, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mfe.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mfe.Generation,
+	})
+}
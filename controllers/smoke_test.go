@@ -0,0 +1,63 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+func TestSmokeCheck(t *testing.T) {
+	ctx := context.Background()
+	bundle := reactBundle(t, "chunk")
+	root := t.TempDir()
+	files, err := cdn.NewFileUploader(root)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.FileServer(http.Dir(root)))
+	defer server.Close()
+	backend := cdn.Backend{Client: files, PublicURL: "https://cdn.example.com"}
+	require.NoError(t, cdn.UploadDirectoryToCDN(ctx, files, bundle, "checkout"))
+
+	recorder := record.NewFakeRecorder(10)
+	r := &controllers.MicroFrontendReconciler{Recorder: recorder, HTTPClient: server.Client()}
+	mfe := &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout", Generation: 2},
+		Spec:       v1alpha1.MicroFrontendSpec{SmokeCheck: &v1alpha1.SmokeCheckSpec{BaseURL: server.URL}},
+		Status:     v1alpha1.MicroFrontendStatus{Synced: true, Digest: "sha256:aaa"},
+	}
+
+	// The spec's base URL takes precedence over the backend's public URL
+	require.NoError(t, controllers.PlanSmokeCheck(mfe, backend, bundle, "remoteEntry.js", "checkout", "sha256:aaa"))
+	assert.Equal(t, server.URL, mfe.Status.SmokeCheck.BaseURL)
+	assert.Len(t, mfe.Status.SmokeCheck.Files, 2)
+	assert.True(t, controllers.SmokeCheck(r, ctx, mfe))
+	ready := meta.FindStatusCondition(mfe.Status.Conditions, v1alpha1.ConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, int64(2), ready.ObservedGeneration)
+	assert.Contains(t, <-recorder.Events, "Normal SmokeCheckPassed Fetched 2 published files")
+
+	require.NoError(t, files.Delete(ctx, "checkout/js/935.e1f2.js"))
+	assert.False(t, controllers.SmokeCheck(r, ctx, mfe))
+	ready = meta.FindStatusCondition(mfe.Status.Conditions, v1alpha1.ConditionReady)
+	assert.Equal(t, "SmokeCheckFailed", ready.Reason)
+	assert.Contains(t, ready.Message, "checkout/js/935.e1f2.js: status 404")
+
+	// Without a public URL the bundle cannot be checked and is not reported Ready
+	mfe.Spec.SmokeCheck = nil
+	require.NoError(t, controllers.PlanSmokeCheck(mfe, cdn.Backend{Client: files}, bundle, "remoteEntry.js", "checkout", "sha256:aaa"))
+	assert.False(t, controllers.SmokeCheck(r, ctx, mfe))
+	ready = meta.FindStatusCondition(mfe.Status.Conditions, v1alpha1.ConditionReady)
+	assert.Equal(t, metav1.ConditionUnknown, ready.Status)
+	assert.Equal(t, "SmokeCheckNotConfigured", ready.Reason)
+}
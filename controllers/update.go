@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle"
+)
+
+// defaultUpdateInterval is how often the registry is polled when the
+// UpdatePolicy does not set an interval.
+const defaultUpdateInterval = 5 * time.Minute
+
+// resolveUpdatePolicy returns the reference to deploy. Without an
+// UpdatePolicy that is Spec.OCIArtifact; otherwise the registry is polled, at
+// most once per interval, for the newest accepted tag, and the result is
+// pinned by digest and recorded in the status. The choice is only reported
+// as a promotion once it is published, see recordPromotion.
+func (r *MicroFrontendReconciler) resolveUpdatePolicy(ctx context.Context, mfe *v1alpha1.MicroFrontend) (string, error) {
+	policy := mfe.Spec.UpdatePolicy
+	if policy == nil {
+		mfe.Status.UpdatePolicy = nil
+		return mfe.Spec.OCIArtifact, nil
+	}
+
+	repository, err := bundle.RepositoryName(mfe.Spec.OCIArtifact)
+	if err != nil {
+		return "", err
+	}
+
+	current := mfe.Status.UpdatePolicy
+	if current != nil && current.Digest != "" && current.ObservedGeneration == mfe.Generation && !updateCheckDue(mfe) {
+		return repository + "@" + current.Digest, nil
+	}
+
+	tagPolicy, err := bundle.NewTagPolicy(policy.SemVer, policy.TagPattern)
+	if err != nil {
+		return "", permanent(err)
+	}
+	tag, desc, err := bundle.LatestTag(ctx, mfe.Spec.OCIArtifact, tagPolicy)
+	if err != nil {
+		return "", err
+	}
+
+	if current == nil {
+		current = &v1alpha1.UpdatePolicyStatus{}
+		mfe.Status.UpdatePolicy = current
+	}
+	current.ObservedGeneration = mfe.Generation
+	current.Tag = tag
+	current.Digest = desc.Digest.String()
+	current.LastCheckedAt = time.Now().Format(time.RFC3339)
+	return fmt.Sprintf("%s@%s", repository, desc.Digest), nil
+}
+
+// recordPromotion reports the tag chosen by the UpdatePolicy as promoted
+// once its digest has been published in place of previous.
+func (r *MicroFrontendReconciler) recordPromotion(mfe *v1alpha1.MicroFrontend, previous string) {
+	current := mfe.Status.UpdatePolicy
+	if current == nil || current.Digest == "" || current.Digest != mfe.Status.Digest || current.Digest == previous {
+		return
+	}
+	if previous == "" {
+		previous = "(none)"
+	}
+	repository, _ := bundle.RepositoryName(mfe.Spec.OCIArtifact)
+	r.Recorder.Eventf(mfe, corev1.EventTypeNormal, "Promoted",
+		"Promoted %s to %s (%s), replacing %s", repository, current.Tag, current.Digest, previous)
+	current.LastPromotedAt = time.Now().Format(time.RFC3339)
+}
+
+// updateInterval returns the registry poll interval of the UpdatePolicy.
+func updateInterval(mfe *v1alpha1.MicroFrontend) time.Duration {
+	if p := mfe.Spec.UpdatePolicy; p != nil && p.Interval != nil && p.Interval.Duration > 0 {
+		return p.Interval.Duration
+	}
+	return defaultUpdateInterval
+}
+
+// updateCheckDue reports whether the registry should be polled again.
+func updateCheckDue(mfe *v1alpha1.MicroFrontend) bool {
+	status := mfe.Status.UpdatePolicy
+	if status == nil || status.LastCheckedAt == "" {
+		return true
+	}
+	last, err := time.Parse(time.RFC3339, status.LastCheckedAt)
+	if err != nil {
+		return true
+	}
+	return time.Since(last) >= updateInterval(mfe)
+}
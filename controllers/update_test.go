@@ -0,0 +1,41 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+)
+
+func TestRecordPromotion(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &controllers.MicroFrontendReconciler{Recorder: recorder}
+	mfe := &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec:       v1alpha1.MicroFrontendSpec{OCIArtifact: "registry.example.com/mfe/checkout:1.0.0"},
+		Status: v1alpha1.MicroFrontendStatus{
+			Digest:       "sha256:aaa",
+			UpdatePolicy: &v1alpha1.UpdatePolicyStatus{Tag: "1.1.0", Digest: "sha256:bbb"},
+		},
+	}
+
+	// A tag chosen but not yet published is not a promotion
+	controllers.RecordPromotion(r, mfe, "sha256:aaa")
+	assert.Empty(t, mfe.Status.UpdatePolicy.LastPromotedAt)
+	assert.Empty(t, recorder.Events)
+
+	mfe.Status.Digest = "sha256:bbb"
+	controllers.RecordPromotion(r, mfe, "sha256:aaa")
+	assert.NotEmpty(t, mfe.Status.UpdatePolicy.LastPromotedAt)
+	assert.Equal(t, "Normal Promoted Promoted registry.example.com/mfe/checkout to 1.1.0 (sha256:bbb), replacing sha256:aaa", <-recorder.Events)
+
+	// Publishing the same digest again is not a promotion
+	mfe.Status.UpdatePolicy.LastPromotedAt = ""
+	controllers.RecordPromotion(r, mfe, "sha256:bbb")
+	assert.Empty(t, mfe.Status.UpdatePolicy.LastPromotedAt)
+	assert.Empty(t, recorder.Events)
+}
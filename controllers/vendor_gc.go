@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// vendorGCFinalizer keeps a SharedModuleVersion until its files have been
+// removed from the CDN.
+const vendorGCFinalizer = "platform.mycorp.com/vendor-gc"
+
+// defaultVendorGCInterval is used when VendorGCReconciler.Interval is unset.
+const defaultVendorGCInterval = time.Hour
+
+// VendorGCReconciler deletes shared module versions under vendor/ that no
+// MicroFrontend uses any more. References are recomputed from the statuses
+// of all MicroFrontends; once a version has been unreferenced for
+// GracePeriod, its SharedModuleVersion is deleted and the finalizer removes
+// its files from the CDN.
+type VendorGCReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	CDN      cdn.Backends
+
+	// GracePeriod is how long a version must stay unreferenced before it
+	// is deleted.
+	GracePeriod time.Duration
+	// Interval is how often each version is checked.
+	Interval time.Duration
+	// DryRun reports what would be deleted without deleting anything.
+	DryRun bool
+}
+
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=sharedmoduleversions/finalizers,verbs=update
+
+func (r *VendorGCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var smv v1alpha1.SharedModuleVersion
+	if err := r.Get(ctx, req.NamespacedName, &smv); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !smv.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.collect(ctx, &smv)
+	}
+	if controllerutil.AddFinalizer(&smv, vendorGCFinalizer) {
+		if err := r.Update(ctx, &smv); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+		}
+	}
+
+	refs, err := r.liveReferences(ctx, &smv)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	now := time.Now()
+	status := smv.Status.DeepCopy()
+	if !sameReferences(status.References, refs) {
+		status.References = refs
+	}
+	since, parseErr := time.Parse(time.RFC3339, status.UnreferencedSince)
+	switch {
+	case len(refs) > 0:
+		if status.UnreferencedSince != "" {
+			r.Recorder.Eventf(&smv, corev1.EventTypeNormal, "Referenced",
+				"%s@%s is used again; it will not be deleted", smv.Spec.Package, smv.Spec.Version)
+		}
+		status.UnreferencedSince = ""
+	case parseErr != nil:
+		since = now
+		status.UnreferencedSince = now.Format(time.RFC3339)
+		r.Recorder.Eventf(&smv, corev1.EventTypeNormal, "Unreferenced",
+			"No MicroFrontend uses %s@%s; its files will be deleted after %s", smv.Spec.Package, smv.Spec.Version, r.GracePeriod)
+	}
+	if !equality.Semantic.DeepEqual(*status, smv.Status) {
+		smv.Status = *status
+		if err := r.Status().Update(ctx, &smv); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update SharedModuleVersion status: %w", err)
+		}
+	}
+	if len(refs) > 0 {
+		return ctrl.Result{RequeueAfter: r.interval()}, nil
+	}
+
+	if remaining := since.Add(r.GracePeriod).Sub(now); remaining > 0 {
+		return ctrl.Result{RequeueAfter: minDuration(remaining, r.interval())}, nil
+	}
+	if r.DryRun {
+		logger.Info("Dry run: would delete unreferenced shared module", "path", smv.Spec.Path, "files", len(smv.Status.Files))
+		r.Recorder.Eventf(&smv, corev1.EventTypeNormal, "DryRun",
+			"Would delete %d files under %s, unreferenced since %s", len(smv.Status.Files), smv.Spec.Path, smv.Status.UnreferencedSince)
+		return ctrl.Result{RequeueAfter: r.interval()}, nil
+	}
+
+	// The precondition fails if a MicroFrontend registered the version since
+	// it was read; registerSharedModule waits while the object is deleting.
+	r.Recorder.Eventf(&smv, corev1.EventTypeNormal, "Deleting",
+		"Deleting %s@%s, unreferenced since %s", smv.Spec.Package, smv.Spec.Version, smv.Status.UnreferencedSince)
+	if err := r.Delete(ctx, &smv, client.Preconditions{UID: &smv.UID, ResourceVersion: &smv.ResourceVersion}); apierrors.IsConflict(err) {
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to delete SharedModuleVersion: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// collect removes the files of a deleted SharedModuleVersion from the CDN
+// and releases its finalizer. Files are kept in dry-run mode and when the
+// backend cannot delete them.
+func (r *VendorGCReconciler) collect(ctx context.Context, smv *v1alpha1.SharedModuleVersion) error {
+	if !controllerutil.ContainsFinalizer(smv, vendorGCFinalizer) {
+		return nil
+	}
+
+	backend, ok := r.CDN[smv.Spec.Backend]
+	switch {
+	case r.DryRun:
+		r.Recorder.Eventf(smv, corev1.EventTypeNormal, "DryRun", "Keeping %d files under %s", len(smv.Status.Files), smv.Spec.Path)
+	case !ok:
+		r.Recorder.Eventf(smv, corev1.EventTypeWarning, "DeleteFailed",
+			"CDN backend %q is not configured; keeping files under %s", smv.Spec.Backend, smv.Spec.Path)
+	default:
+		err := cdn.DeleteFiles(ctx, backend.Client, smv.Spec.Path, smv.Status.Files)
+		if errors.Is(err, cdn.ErrDeleteUnsupported) {
+			r.Recorder.Eventf(smv, corev1.EventTypeWarning, "DeleteFailed",
+				"CDN backend %q cannot delete files; keeping files under %s", smv.Spec.Backend, smv.Spec.Path)
+			break
+		}
+		if err != nil {
+			r.Recorder.Eventf(smv, corev1.EventTypeWarning, "DeleteFailed", "Failed to delete files under %s: %v", smv.Spec.Path, err)
+			return err
+		}
+		r.Recorder.Eventf(smv, corev1.EventTypeNormal, "Deleted", "Deleted %d files under %s", len(smv.Status.Files), smv.Spec.Path)
+	}
+
+	controllerutil.RemoveFinalizer(smv, vendorGCFinalizer)
+	if err := r.Update(ctx, smv); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return nil
+}
+
+// liveReferences returns the MicroFrontends whose status lists smv's path
+// on smv's backend, either for the last published bundle or for a rollout
+// version that still receives traffic.
+func (r *VendorGCReconciler) liveReferences(ctx context.Context, smv *v1alpha1.SharedModuleVersion) ([]v1alpha1.SharedModuleReference, error) {
+	var list v1alpha1.MicroFrontendList
+	if err := r.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("failed to list MicroFrontends: %w", err)
+	}
+	var refs []v1alpha1.SharedModuleReference
+	for _, mfe := range list.Items {
+		backend, _, err := cdn.SplitTarget(mfe.Spec.CDNTarget)
+		if err != nil || backend != smv.Spec.Backend {
+			continue
+		}
+		paths := vendorPaths(mfe.Status.SharedModules)
+		if st := mfe.Status.Rollout; st != nil {
+			paths = append(paths, st.Stable.SharedModules...)
+			if st.Phase == v1alpha1.RolloutProgressing && st.Canary != nil {
+				paths = append(paths, st.Canary.SharedModules...)
+			}
+		}
+		for _, p := range paths {
+			if p == smv.Spec.Path {
+				refs = append(refs, v1alpha1.SharedModuleReference{Namespace: mfe.Namespace, Name: mfe.Name})
+				break
+			}
+		}
+	}
+	return refs, nil
+}
+
+func (r *VendorGCReconciler) interval() time.Duration {
+	if r.Interval > 0 {
+		return r.Interval
+	}
+	return defaultVendorGCInterval
+}
+
+// sameReferences reports whether a and b hold the same references in any
+// order.
+func sameReferences(a, b []v1alpha1.SharedModuleReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, ref := range b {
+		if !hasReference(a, ref) {
+			return false
+		}
+	}
+	return true
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (r *VendorGCReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("vendorgc").
+		For(&v1alpha1.SharedModuleVersion{}).
+		Complete(r)
+}
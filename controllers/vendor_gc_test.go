@@ -0,0 +1,182 @@
+package controllers_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// memoryCDN holds uploaded files by remote path.
+type memoryCDN map[string][]byte
+
+func (m memoryCDN) Upload(ctx context.Context, localPath, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	m[remotePath] = data
+	return nil
+}
+
+func (m memoryCDN) Download(ctx context.Context, remotePath string) ([]byte, error) {
+	data, ok := m[remotePath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (m memoryCDN) Delete(ctx context.Context, remotePath string) error {
+	delete(m, remotePath)
+	return nil
+}
+
+func (m memoryCDN) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	for name := range m {
+		if strings.HasPrefix(name, prefix+"/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func lodashVersion(version string) *v1alpha1.SharedModuleVersion {
+	return &v1alpha1.SharedModuleVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "lodash-" + version},
+		Spec:       v1alpha1.SharedModuleVersionSpec{Backend: "primary", Package: "lodash", Version: version, Path: "vendor/lodash@" + version},
+		Status: v1alpha1.SharedModuleVersionStatus{
+			ContentHash: "sha256:" + version,
+			Files:       []string{"js/12.js"},
+			References:  []v1alpha1.SharedModuleReference{{Namespace: "shop", Name: "checkout"}},
+		},
+	}
+}
+
+func TestVendorGC(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	checkout := &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec:       v1alpha1.MicroFrontendSpec{CDNTarget: "primary/apps/checkout"},
+		Status: v1alpha1.MicroFrontendStatus{SharedModules: []v1alpha1.SharedModuleStatus{
+			{Name: "lodash", Version: "4.17.21", Path: "vendor/lodash@4.17.21", Files: []string{"js/12.js"}},
+		}},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(checkout, lodashVersion("4.17.20"), lodashVersion("4.17.21")).Build()
+	files := memoryCDN{"vendor/lodash@4.17.20/js/12.js": []byte("old"), "vendor/lodash@4.17.21/js/12.js": []byte("new")}
+	recorder := record.NewFakeRecorder(10)
+	r := &controllers.VendorGCReconciler{
+		Client:      k8s,
+		Recorder:    recorder,
+		CDN:         cdn.Backends{"primary": {Client: files}},
+		GracePeriod: time.Hour,
+		Interval:    10 * time.Minute,
+		DryRun:      true,
+	}
+	ctx := context.Background()
+	reconcile := func(name string) (ctrl.Result, v1alpha1.SharedModuleVersion) {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: name}})
+		require.NoError(t, err)
+		var smv v1alpha1.SharedModuleVersion
+		if err := k8s.Get(ctx, client.ObjectKey{Name: name}, &smv); !apierrors.IsNotFound(err) {
+			require.NoError(t, err)
+		}
+		return result, smv
+	}
+
+	// Referenced versions are kept
+	result, smv := reconcile("lodash-4.17.21")
+	assert.Equal(t, 10*time.Minute, result.RequeueAfter)
+	assert.Empty(t, smv.Status.UnreferencedSince)
+	assert.Contains(t, smv.Finalizers, "platform.mycorp.com/vendor-gc")
+
+	// Unreferenced versions wait for the grace period
+	result, smv = reconcile("lodash-4.17.20")
+	assert.Equal(t, 10*time.Minute, result.RequeueAfter)
+	assert.NotEmpty(t, smv.Status.UnreferencedSince)
+	assert.Empty(t, smv.Status.References)
+	assert.Contains(t, <-recorder.Events, "Unreferenced")
+
+	smv.Status.UnreferencedSince = time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	require.NoError(t, k8s.Status().Update(ctx, &smv))
+
+	// A dry run only reports what would be deleted
+	_, smv = reconcile("lodash-4.17.20")
+	assert.Contains(t, <-recorder.Events, "DryRun")
+	assert.True(t, smv.DeletionTimestamp.IsZero())
+	assert.Len(t, files, 2)
+
+	// Otherwise the version is deleted and the finalizer removes its files
+	r.DryRun = false
+	_, smv = reconcile("lodash-4.17.20")
+	assert.Contains(t, <-recorder.Events, "Deleting")
+	assert.False(t, smv.DeletionTimestamp.IsZero())
+	_, smv = reconcile("lodash-4.17.20")
+	assert.Contains(t, <-recorder.Events, "Deleted")
+	assert.Empty(t, smv.Name)
+	assert.Equal(t, memoryCDN{"vendor/lodash@4.17.21/js/12.js": []byte("new")}, files)
+}
+
+func TestVendorGCKeepsLiveRolloutVersions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	checkout := &v1alpha1.MicroFrontend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "checkout"},
+		Spec:       v1alpha1.MicroFrontendSpec{CDNTarget: "primary/apps/checkout"},
+		Status: v1alpha1.MicroFrontendStatus{
+			SharedModules: []v1alpha1.SharedModuleStatus{
+				{Name: "lodash", Version: "4.17.21", Path: "vendor/lodash@4.17.21", Files: []string{"js/12.js"}},
+			},
+			Rollout: &v1alpha1.RolloutStatus{
+				Phase:  v1alpha1.RolloutProgressing,
+				Stable: v1alpha1.RolloutVersion{Digest: "sha256:aaa", SharedModules: []string{"vendor/lodash@4.17.20"}},
+				Canary: &v1alpha1.RolloutVersion{Digest: "sha256:bbb", SharedModules: []string{"vendor/lodash@4.17.21"}},
+				Weight: 10,
+			},
+		},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(checkout, lodashVersion("4.17.20")).Build()
+	r := &controllers.VendorGCReconciler{
+		Client:      k8s,
+		Recorder:    record.NewFakeRecorder(10),
+		CDN:         cdn.Backends{"primary": {Client: memoryCDN{}}},
+		GracePeriod: time.Hour,
+	}
+	ctx := context.Background()
+	reconcile := func() v1alpha1.SharedModuleVersion {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: "lodash-4.17.20"}})
+		require.NoError(t, err)
+		var smv v1alpha1.SharedModuleVersion
+		require.NoError(t, k8s.Get(ctx, client.ObjectKey{Name: "lodash-4.17.20"}, &smv))
+		return smv
+	}
+
+	// The stable version still loads the old lodash while the canary rolls out
+	smv := reconcile()
+	assert.Empty(t, smv.Status.UnreferencedSince)
+	assert.Equal(t, []v1alpha1.SharedModuleReference{{Namespace: "shop", Name: "checkout"}}, smv.Status.References)
+
+	// Once the canary is promoted nothing loads it any more
+	checkout.Status.Rollout = &v1alpha1.RolloutStatus{Phase: v1alpha1.RolloutPromoted, Stable: *checkout.Status.Rollout.Canary}
+	require.NoError(t, k8s.Status().Update(ctx, checkout))
+	smv = reconcile()
+	assert.NotEmpty(t, smv.Status.UnreferencedSince)
+	assert.Empty(t, smv.Status.References)
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/bundle/verify"
+)
+
+//+kubebuilder:rbac:groups=platform.mycorp.com,resources=verificationpolicies,verbs=get;list;watch
+
+// verifyArtifact checks art against every VerificationPolicy whose
+// repositories match it and records the outcome as the Verified condition.
+// It returns false when the bundle must not be published. Errors are
+// reserved for failures to evaluate the policies at all.
+func (r *MicroFrontendReconciler) verifyArtifact(ctx context.Context, mfe *v1alpha1.MicroFrontend, art *bundle.Artifact) (bool, error) {
+	var policies v1alpha1.VerificationPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return false, fmt.Errorf("failed to list verification policies: %w", err)
+	}
+
+	repository := art.Repository.Reference.Registry + "/" + art.Repository.Reference.Repository
+	var signers []string
+	for i := range policies.Items {
+		p := &policies.Items[i]
+		if !policyApplies(p, repository) || (p.Spec.Cosign == nil && p.Spec.Notation == nil) {
+			continue
+		}
+
+		policy, err := toVerifyPolicy(p)
+		if err != nil {
+			setVerified(mfe, metav1.ConditionFalse, "InvalidPolicy", fmt.Sprintf("VerificationPolicy %s: %v", p.Name, err))
+			return false, nil
+		}
+		res, err := verify.Verify(ctx, art.Repository, art.Manifest, policy)
+		switch {
+		case errors.Is(err, verify.ErrUnsigned):
+			setVerified(mfe, metav1.ConditionFalse, "Unsigned",
+				fmt.Sprintf("%s is not signed as required by VerificationPolicy %s", art.Manifest.Digest, p.Name))
+			return false, nil
+		case errors.Is(err, verify.ErrUntrusted):
+			setVerified(mfe, metav1.ConditionFalse, "SignatureMismatch",
+				fmt.Sprintf("%s does not satisfy VerificationPolicy %s: %v", art.Manifest.Digest, p.Name, err))
+			return false, nil
+		case err != nil:
+			return false, fmt.Errorf("failed to verify signatures: %w", err)
+		}
+		signers = append(signers, fmt.Sprintf("%s (%s, policy %s)", res.Signer, res.Scheme, p.Name))
+	}
+
+	if len(signers) == 0 {
+		setVerified(mfe, metav1.ConditionTrue, "NoPolicy", "No VerificationPolicy requires signatures for "+repository)
+		return true, nil
+	}
+	setVerified(mfe, metav1.ConditionTrue, "SignatureVerified", "Signed by "+strings.Join(signers, ", "))
+	return true, nil
+}
+
+func setVerified(mfe *v1alpha1.MicroFrontend, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mfe.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionVerified,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mfe.Generation,
+	})
+}
+
+func policyApplies(p *v1alpha1.VerificationPolicy, repository string) bool {
+	if len(p.Spec.Repositories) == 0 {
+		return true
+	}
+	for _, pattern := range p.Spec.Repositories {
+		if repositoryMatches(pattern, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryMatches reports whether repository matches the glob pattern.
+// Unlike path.Match, "*" also matches "/", so "ghcr.io/mycorp/*" covers
+// nested repositories such as "ghcr.io/mycorp/team/app" rather than letting
+// them bypass the policy.
+func repositoryMatches(pattern, repository string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.MustCompile("^" + expr + "$").MatchString(repository)
+}
+
+// toVerifyPolicy parses the keys, certificates and identities of a policy.
+func toVerifyPolicy(p *v1alpha1.VerificationPolicy) (verify.Policy, error) {
+	var policy verify.Policy
+	var err error
+	if p.Spec.Cosign != nil {
+		if policy.Cosign, err = toVerifyTrust(p.Spec.Cosign); err != nil {
+			return policy, fmt.Errorf("cosign: %w", err)
+		}
+	}
+	if p.Spec.Notation != nil {
+		if len(p.Spec.Notation.PublicKeys) > 0 {
+			return policy, errors.New("notation: public keys are not supported, use rootCertificates")
+		}
+		if policy.Notation, err = toVerifyTrust(p.Spec.Notation); err != nil {
+			return policy, fmt.Errorf("notation: %w", err)
+		}
+	}
+	return policy, nil
+}
+
+func toVerifyTrust(t *v1alpha1.SignatureTrust) (*verify.Trust, error) {
+	trust := &verify.Trust{}
+	for _, keyPEM := range t.PublicKeys {
+		key, err := verify.ParsePublicKeyPEM([]byte(keyPEM))
+		if err != nil {
+			return nil, err
+		}
+		trust.PublicKeys = append(trust.PublicKeys, key)
+	}
+	for _, keyPEM := range t.TransparencyLogKeys {
+		key, err := verify.ParsePublicKeyPEM([]byte(keyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("transparency log key: %w", err)
+		}
+		trust.TransparencyLogKeys = append(trust.TransparencyLogKeys, key)
+	}
+	if len(t.RootCertificates) > 0 {
+		trust.Roots = x509.NewCertPool()
+		for _, certPEM := range t.RootCertificates {
+			if !trust.Roots.AppendCertsFromPEM([]byte(certPEM)) {
+				return nil, errors.New("failed to parse root certificate")
+			}
+		}
+	}
+	for _, id := range t.Identities {
+		// Anchored so that a pattern cannot match inside a longer identity
+		re, err := regexp.Compile(`^(?:` + id.Subject + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity subject %q: %w", id.Subject, err)
+		}
+		trust.Identities = append(trust.Identities, verify.Identity{Subject: re, Issuer: id.Issuer})
+	}
+	return trust, nil
+}
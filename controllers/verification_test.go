@@ -0,0 +1,60 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"mfe-operator/api/v1alpha1"
+	"mfe-operator/controllers"
+)
+
+func TestIdentitySubjectsAreAnchored(t *testing.T) {
+	trust, err := controllers.ToVerifyTrust(&v1alpha1.SignatureTrust{
+		Identities: []v1alpha1.CertificateIdentity{
+			{Subject: `ci@mycorp\.com`},
+			{Subject: `https://github\.com/mycorp/.+|release@mycorp\.com`},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]bool{
+		"ci@mycorp.com":                                   true,
+		"evil-ci@mycorp.com":                              false,
+		"ci@mycorp.com.attacker.example":                  false,
+		"https://github.com/mycorp/cart":                  true,
+		"https://evil.com/https://github.com/mycorp/cart": false,
+		"release@mycorp.com":                              true,
+		"release@mycorp.com.attacker.example":             false,
+	}
+	for name, want := range tests {
+		var matched bool
+		for _, id := range trust.Identities {
+			matched = matched || id.Subject.MatchString(name)
+		}
+		assert.Equal(t, want, matched, name)
+	}
+}
+
+func TestPolicyAppliesToNestedRepositories(t *testing.T) {
+	policy := &v1alpha1.VerificationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycorp"},
+		Spec:       v1alpha1.VerificationPolicySpec{Repositories: []string{"ghcr.io/mycorp/*", "registry.example.com/mfe/app-?"}},
+	}
+	tests := map[string]bool{
+		"ghcr.io/mycorp/checkout":          true,
+		"ghcr.io/mycorp/team/checkout":     true,
+		"ghcr.io/mycorp/team/sub/checkout": true,
+		"ghcr.io/mycorpx/checkout":         false,
+		"ghcr.io/other/checkout":           false,
+		"registry.example.com/mfe/app-1":   true,
+		"registry.example.com/mfe/app-12":  false,
+		"registry.example.com/mfeXapp-1":   false,
+	}
+	for repository, want := range tests {
+		assert.Equal(t, want, controllers.PolicyApplies(policy, repository), repository)
+	}
+	assert.True(t, controllers.PolicyApplies(&v1alpha1.VerificationPolicy{}, "ghcr.io/anything/at/all"))
+}
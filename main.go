@@ -2,18 +2,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	"sigs.k8s.io/controller-runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	platformv1alpha1 "mfe-operator/api/v1alpha1"
+	platformv1beta1 "mfe-operator/api/v1beta1"
 	"mfe-operator/controllers"
+	"mfe-operator/pkg/bundle"
+	"mfe-operator/pkg/bundle/cdn"
 )
 
 var (
@@ -24,13 +31,44 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(platformv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(platformv1beta1.AddToScheme(scheme))
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var cacheDir string
+	var cacheMaxBytes int64
+	var workspaceDir string
+	var workspaceStrategy string
+	var workspaceMaxBytes int64
+	var cdnConfig string
+	var driftCheck bool
+	var resyncPeriod time.Duration
+	var enableWebhooks bool
+	var vendorGCGracePeriod time.Duration
+	var vendorGCInterval time.Duration
+	var vendorGCDryRun bool
+	var catalogSelector string
+	var catalogTarget string
+	var catalogConfigMap string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+	flag.StringVar(&cacheDir, "cache-dir", "/var/cache/mfe-operator", "Directory for the persistent bundle cache; empty disables caching.")
+	flag.Int64Var(&cacheMaxBytes, "cache-max-bytes", 10<<30, "Maximum size of the bundle cache in bytes; 0 disables eviction.")
+	flag.StringVar(&workspaceDir, "workspace-dir", os.TempDir(), "Base directory for per-reconcile fetch and extract workspaces.")
+	flag.StringVar(&workspaceStrategy, "workspace-strategy", bundle.IsolatedTempDir.String(), "Workspace naming strategy: IsolatedTempDir, UseCRName or UseUUID.")
+	flag.Int64Var(&workspaceMaxBytes, "workspace-max-bytes", 5<<30, "Maximum disk usage of reconcile workspaces in bytes; 0 disables the limit.")
+	flag.StringVar(&cdnConfig, "cdn-config", "", "Path to the CDN backends configuration file; without it no MicroFrontend can be published.")
+	flag.BoolVar(&driftCheck, "drift-check", false, "Re-read the CDN manifest on every resync and republish if it no longer matches.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute, "Delay between periodic reconciles of a MicroFrontend; Spec.SyncInterval overrides it.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true, "Serve the MicroFrontend defaulting and validating admission webhooks.")
+	flag.DurationVar(&vendorGCGracePeriod, "vendor-gc-grace-period", 72*time.Hour, "How long a shared module version under vendor/ must be unreferenced before it is deleted.")
+	flag.DurationVar(&vendorGCInterval, "vendor-gc-interval", time.Hour, "Delay between garbage collection checks of each shared module version.")
+	flag.BoolVar(&vendorGCDryRun, "vendor-gc-dry-run", false, "Report unreferenced shared module versions without deleting them.")
+	flag.StringVar(&catalogSelector, "catalog-selector", "", "Label selector for the MicroFrontends listed in the remote catalog; empty lists all.")
+	flag.StringVar(&catalogTarget, "catalog-cdn-target", "", "CDN target (<backend>/<prefix>) to publish remotes.json and importmap.json to; empty disables.")
+	flag.StringVar(&catalogConfigMap, "catalog-configmap", "", "ConfigMap (<namespace>/<name>) to write remotes.json and importmap.json to; empty disables.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
@@ -47,13 +85,116 @@ func main() {
 		os.Exit(1)
 	}
 
+	strategy, err := bundle.ParseTarballNamingStrategy(workspaceStrategy)
+	if err != nil {
+		setupLog.Error(err, "invalid --workspace-strategy")
+		os.Exit(1)
+	}
+	workspaces, err := bundle.NewWorkspaceManager(workspaceDir, strategy, workspaceMaxBytes)
+	if err != nil {
+		setupLog.Error(err, "unable to set up workspaces")
+		os.Exit(1)
+	}
+
+	var cache *bundle.BlobCache
+	if cacheDir != "" {
+		if cache, err = bundle.NewBlobCache(cacheDir, cacheMaxBytes); err != nil {
+			setupLog.Error(err, "unable to open bundle cache", "dir", cacheDir)
+			os.Exit(1)
+		}
+		cache.ChargeLoadsTo(workspaces)
+	}
+
+	backends := cdn.Backends{}
+	if cdnConfig != "" {
+		cdnCfg, err := cdn.LoadConfig(cdnConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load CDN config", "path", cdnConfig)
+			os.Exit(1)
+		}
+		if backends, err = cdn.NewBackends(context.Background(), cdnCfg); err != nil {
+			setupLog.Error(err, "unable to set up CDN backends")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("no --cdn-config given, MicroFrontends will not be published")
+	}
+
 	if err = (&controllers.MicroFrontendReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("microfrontend-controller"),
+		Workspaces:    workspaces,
+		Cache:         cache,
+		CDN:           backends,
+		CatalogTarget: catalogTarget,
+		DriftCheck:    driftCheck,
+		ResyncPeriod:  resyncPeriod,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MicroFrontend")
 		os.Exit(1)
 	}
+	if err = (&controllers.SharedModuleReportReconciler{Client: mgr.GetClient()}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SharedModuleReport")
+		os.Exit(1)
+	}
+	if err = (&controllers.VendorGCReconciler{
+		Client:      mgr.GetClient(),
+		Recorder:    mgr.GetEventRecorderFor("vendor-gc"),
+		CDN:         backends,
+		GracePeriod: vendorGCGracePeriod,
+		Interval:    vendorGCInterval,
+		DryRun:      vendorGCDryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VendorGC")
+		os.Exit(1)
+	}
+	if err = (&controllers.MicroFrontendHostReconciler{Client: mgr.GetClient(), CDN: backends}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MicroFrontendHost")
+		os.Exit(1)
+	}
+	if err = (&controllers.PromotionReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("promotion-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Promotion")
+		os.Exit(1)
+	}
+	if catalogTarget != "" || catalogConfigMap != "" {
+		selector, err := labels.Parse(catalogSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid --catalog-selector")
+			os.Exit(1)
+		}
+		var configMap types.NamespacedName
+		if catalogConfigMap != "" {
+			namespace, name, ok := strings.Cut(catalogConfigMap, "/")
+			if !ok || namespace == "" || name == "" {
+				setupLog.Info("invalid --catalog-configmap, expected <namespace>/<name>", "value", catalogConfigMap)
+				os.Exit(1)
+			}
+			configMap = types.NamespacedName{Namespace: namespace, Name: name}
+		}
+		if err = (&controllers.RemoteCatalogReconciler{
+			Client:    mgr.GetClient(),
+			CDN:       backends,
+			Selector:  selector,
+			CDNTarget: catalogTarget,
+			ConfigMap: configMap,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "RemoteCatalog")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		// Registering a webhook for a convertible kind also serves /convert
+		if err = (&controllers.MicroFrontendWebhook{Client: mgr.GetClient(), CDN: backends, CatalogTarget: catalogTarget}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MicroFrontend")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -0,0 +1,345 @@
+// File: pkg/bundle/attestations.go
+package bundle
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"mfe-operator/pkg/bundle/verify"
+)
+
+const (
+	spdxMediaType      = "application/spdx+json"
+	cyclonedxMediaType = "application/vnd.cyclonedx+json"
+	inTotoMediaType    = "application/vnd.in-toto+json"
+	dsseMediaType      = "application/vnd.dsse.envelope.v1+json"
+	// sigstoreBundlePrefix matches every version of the Sigstore bundle format.
+	sigstoreBundlePrefix = "application/vnd.dev.sigstore.bundle"
+
+	spdxPredicateType      = "https://spdx.dev/Document"
+	cyclonedxPredicateType = "https://cyclonedx.org/bom"
+	slsaV02PredicateType   = "https://slsa.dev/provenance/v0.2"
+	slsaV1PredicateType    = "https://slsa.dev/provenance/v1"
+
+	maxAttestationBytes = 16 << 20
+)
+
+// Attestations summarizes the SBOM and provenance attached to an artifact.
+// SBOMs are read as published; provenance signatures are left for the
+// caller to check against its policy, see Provenance.Envelope.
+type Attestations struct {
+	// SBOMFormat is "spdx" or "cyclonedx", or empty when no SBOM was found.
+	SBOMFormat   string
+	PackageCount int
+
+	// Provenance lists the SLSA provenance statements about the artifact,
+	// in the order they were found.
+	Provenance []Provenance
+	// BuilderID, SourceRepo and SourceCommit describe the first provenance
+	// statement.
+	BuilderID    string
+	SourceRepo   string
+	SourceCommit string
+}
+
+// Provenance is a SLSA provenance statement about the artifact.
+type Provenance struct {
+	BuilderID    string
+	SourceRepo   string
+	SourceCommit string
+	// Envelope is the signed envelope the statement was read from, or nil
+	// when it was published unsigned.
+	Envelope *verify.Envelope
+}
+
+// inTotoStatement is an in-toto attestation statement (v0.1 or v1).
+type inTotoStatement struct {
+	Subject []struct {
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+	Signatures  []struct {
+		Sig string `json:"sig"`
+	} `json:"signatures"`
+}
+
+// sigstoreBundle is the subset of a Sigstore bundle (v0.1 to v0.3) holding
+// a DSSE envelope and its signing certificate.
+type sigstoreBundle struct {
+	VerificationMaterial struct {
+		Certificate *struct {
+			RawBytes []byte `json:"rawBytes"`
+		} `json:"certificate"`
+		X509CertificateChain *struct {
+			Certificates []struct {
+				RawBytes []byte `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"x509CertificateChain"`
+	} `json:"verificationMaterial"`
+	DSSEEnvelope *dsseEnvelope `json:"dsseEnvelope"`
+}
+
+// Layer annotations cosign attaches the signing certificate of an
+// attestation with.
+const (
+	cosignCertificateKey = "dev.sigstore.cosign/certificate"
+	cosignChainKey       = "dev.sigstore.cosign/chain"
+)
+
+// DiscoverAttestations lists the referrers of subject and summarizes any
+// SPDX or CycloneDX SBOM and SLSA provenance among them. In-toto statements
+// that do not name subject are ignored.
+func DiscoverAttestations(ctx context.Context, store content.ReadOnlyGraphStorage, subject ocispec.Descriptor) (*Attestations, error) {
+	referrers, err := registry.Referrers(ctx, store, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers: %w", err)
+	}
+
+	att := &Attestations{}
+	for _, ref := range referrers {
+		if !isAttestationType(ref.ArtifactType) {
+			continue
+		}
+		data, err := content.FetchAll(ctx, store, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch attestation manifest: %w", err)
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode attestation manifest: %w", err)
+		}
+		for _, layer := range manifest.Layers {
+			if layer.Size > maxAttestationBytes {
+				log.FromContext(ctx).Info("Skipping oversized attestation", "digest", layer.Digest, "bytes", layer.Size)
+				continue
+			}
+			doc, err := content.FetchAll(ctx, store, layer)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch attestation %s: %w", layer.Digest, err)
+			}
+			if err := att.add(layer, doc, subject.Digest); err != nil {
+				log.FromContext(ctx).Info("Skipping unreadable attestation", "digest", layer.Digest, "reason", err.Error())
+			}
+		}
+	}
+	return att, nil
+}
+
+func isAttestationType(mediaType string) bool {
+	switch mediaType {
+	case spdxMediaType, cyclonedxMediaType, inTotoMediaType, dsseMediaType:
+		return true
+	}
+	return strings.HasPrefix(mediaType, sigstoreBundlePrefix)
+}
+
+// add folds one attestation document into the summary.
+func (a *Attestations) add(layer ocispec.Descriptor, doc []byte, subject digest.Digest) error {
+	if strings.HasPrefix(layer.MediaType, sigstoreBundlePrefix) {
+		var b sigstoreBundle
+		if err := json.Unmarshal(doc, &b); err != nil {
+			return err
+		}
+		if b.DSSEEnvelope == nil {
+			return nil
+		}
+		var certs []*x509.Certificate
+		var raw [][]byte
+		if c := b.VerificationMaterial.Certificate; c != nil {
+			raw = append(raw, c.RawBytes)
+		}
+		if c := b.VerificationMaterial.X509CertificateChain; c != nil {
+			for _, cert := range c.Certificates {
+				raw = append(raw, cert.RawBytes)
+			}
+		}
+		for _, der := range raw {
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return fmt.Errorf("malformed certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+		return a.addEnvelope(*b.DSSEEnvelope, certs, subject)
+	}
+
+	switch layer.MediaType {
+	case spdxMediaType:
+		return a.addSPDX(doc)
+	case cyclonedxMediaType:
+		return a.addCycloneDX(doc)
+	case dsseMediaType:
+		var env dsseEnvelope
+		if err := json.Unmarshal(doc, &env); err != nil {
+			return err
+		}
+		certs, err := verify.ParseCertificatesPEM([]byte(layer.Annotations[cosignCertificateKey] + layer.Annotations[cosignChainKey]))
+		if err != nil {
+			return err
+		}
+		return a.addEnvelope(env, certs, subject)
+	case inTotoMediaType:
+		return a.addStatement(doc, nil, subject)
+	}
+	return nil
+}
+
+func (a *Attestations) addEnvelope(env dsseEnvelope, certs []*x509.Certificate, subject digest.Digest) error {
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return fmt.Errorf("malformed DSSE payload: %w", err)
+	}
+	signed := &verify.Envelope{PayloadType: env.PayloadType, Payload: payload, Certificates: certs}
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			return fmt.Errorf("malformed DSSE signature: %w", err)
+		}
+		signed.Signatures = append(signed.Signatures, sig)
+	}
+	return a.addStatement(payload, signed, subject)
+}
+
+// addStatement folds an in-toto statement into the summary; env is the
+// envelope it was read from, if any.
+func (a *Attestations) addStatement(doc []byte, env *verify.Envelope, subject digest.Digest) error {
+	var stmt inTotoStatement
+	if err := json.Unmarshal(doc, &stmt); err != nil {
+		return err
+	}
+	if !stmt.names(subject) {
+		return fmt.Errorf("statement is not about %s", subject)
+	}
+	switch {
+	case strings.HasPrefix(stmt.PredicateType, spdxPredicateType):
+		return a.addSPDX(stmt.Predicate)
+	case strings.HasPrefix(stmt.PredicateType, cyclonedxPredicateType):
+		return a.addCycloneDX(stmt.Predicate)
+	case stmt.PredicateType == slsaV02PredicateType:
+		return a.addProvenanceV02(stmt.Predicate, env)
+	case stmt.PredicateType == slsaV1PredicateType:
+		return a.addProvenanceV1(stmt.Predicate, env)
+	}
+	return nil
+}
+
+// names reports whether subject is among the statement's subjects.
+func (s *inTotoStatement) names(subject digest.Digest) bool {
+	for _, sub := range s.Subject {
+		if sub.Digest[subject.Algorithm().String()] == subject.Encoded() {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Attestations) addSPDX(doc []byte) error {
+	var sbom struct {
+		Packages []json.RawMessage `json:"packages"`
+	}
+	if err := json.Unmarshal(doc, &sbom); err != nil {
+		return err
+	}
+	a.SBOMFormat = "spdx"
+	a.PackageCount = len(sbom.Packages)
+	return nil
+}
+
+func (a *Attestations) addCycloneDX(doc []byte) error {
+	var sbom struct {
+		Components []json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(doc, &sbom); err != nil {
+		return err
+	}
+	a.SBOMFormat = "cyclonedx"
+	a.PackageCount = len(sbom.Components)
+	return nil
+}
+
+func (a *Attestations) addProvenanceV02(doc []byte, env *verify.Envelope) error {
+	var p struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Invocation struct {
+			ConfigSource struct {
+				URI    string            `json:"uri"`
+				Digest map[string]string `json:"digest"`
+			} `json:"configSource"`
+		} `json:"invocation"`
+	}
+	if err := json.Unmarshal(doc, &p); err != nil {
+		return err
+	}
+	a.addProvenance(Provenance{
+		BuilderID:    p.Builder.ID,
+		SourceRepo:   trimSourceURI(p.Invocation.ConfigSource.URI),
+		SourceCommit: p.Invocation.ConfigSource.Digest["sha1"],
+		Envelope:     env,
+	})
+	return nil
+}
+
+func (a *Attestations) addProvenanceV1(doc []byte, env *verify.Envelope) error {
+	var p struct {
+		BuildDefinition struct {
+			ResolvedDependencies []struct {
+				URI    string            `json:"uri"`
+				Digest map[string]string `json:"digest"`
+			} `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+		} `json:"runDetails"`
+	}
+	if err := json.Unmarshal(doc, &p); err != nil {
+		return err
+	}
+	prov := Provenance{BuilderID: p.RunDetails.Builder.ID, Envelope: env}
+	// By convention the first resolved dependency is the source repository.
+	if deps := p.BuildDefinition.ResolvedDependencies; len(deps) > 0 {
+		prov.SourceRepo = trimSourceURI(deps[0].URI)
+		prov.SourceCommit = deps[0].Digest["gitCommit"]
+		if prov.SourceCommit == "" {
+			prov.SourceCommit = deps[0].Digest["sha1"]
+		}
+	}
+	a.addProvenance(prov)
+	return nil
+}
+
+func (a *Attestations) addProvenance(p Provenance) {
+	if len(a.Provenance) == 0 {
+		a.BuilderID, a.SourceRepo, a.SourceCommit = p.BuilderID, p.SourceRepo, p.SourceCommit
+	}
+	a.Provenance = append(a.Provenance, p)
+}
+
+// trimSourceURI turns "git+https://github.com/org/repo@refs/heads/main" into
+// "https://github.com/org/repo".
+func trimSourceURI(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+	if i := strings.LastIndex(uri, "@"); i > strings.Index(uri, "://")+2 {
+		uri = uri[:i]
+	}
+	return uri
+}
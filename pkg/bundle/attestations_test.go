@@ -0,0 +1,96 @@
+// File: pkg/bundle/attestations_test.go
+package bundle_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+
+	"mfe-operator/pkg/bundle"
+)
+
+func pushBytes(t *testing.T, store *memory.Store, mediaType string, data []byte) ocispec.Descriptor {
+	desc := content.NewDescriptorFromBytes(mediaType, data)
+	if err := store.Push(context.Background(), desc, bytes.NewReader(data)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		require.NoError(t, err)
+	}
+	return desc
+}
+
+func pushManifest(t *testing.T, store *memory.Store, manifest ocispec.Manifest) ocispec.Descriptor {
+	manifest.SchemaVersion = 2
+	manifest.MediaType = ocispec.MediaTypeImageManifest
+	manifest.Config = pushBytes(t, store, ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	return pushBytes(t, store, ocispec.MediaTypeImageManifest, data)
+}
+
+func attach(t *testing.T, store *memory.Store, subject ocispec.Descriptor, artifactType string, doc []byte) {
+	layer := pushBytes(t, store, artifactType, doc)
+	pushManifest(t, store, ocispec.Manifest{ArtifactType: artifactType, Layers: []ocispec.Descriptor{layer}, Subject: &subject})
+}
+
+func TestDiscoverAttestations(t *testing.T) {
+	store := memory.New()
+	layer := pushBytes(t, store, ocispec.MediaTypeImageLayerGzip, []byte("bundle"))
+	subject := pushManifest(t, store, ocispec.Manifest{Layers: []ocispec.Descriptor{layer}})
+
+	attach(t, store, subject, "application/spdx+json",
+		[]byte(`{"spdxVersion":"SPDX-2.3","packages":[{"name":"react"},{"name":"react-dom"},{"name":"lodash"}]}`))
+
+	statement := `{"_type":"https://in-toto.io/Statement/v1",` +
+		`"subject":[{"name":"ghcr.io/mycorp/cart","digest":{"sha256":"` + subject.Digest.Encoded() + `"}}],` +
+		`"predicateType":"https://slsa.dev/provenance/v1","predicate":{` +
+		`"buildDefinition":{"resolvedDependencies":[{"uri":"git+https://github.com/mycorp/cart@refs/heads/main","digest":{"gitCommit":"abc123"}}]},` +
+		`"runDetails":{"builder":{"id":"https://github.com/actions/runner/github-hosted"}}}}`
+	envelope, err := json.Marshal(map[string]any{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString([]byte(statement)),
+		"signatures":  []map[string]string{{"sig": base64.StdEncoding.EncodeToString([]byte("signature"))}},
+	})
+	require.NoError(t, err)
+	attach(t, store, subject, "application/vnd.dsse.envelope.v1+json", envelope)
+
+	// Provenance about another artifact is ignored
+	other := strings.Replace(statement, subject.Digest.Encoded(), strings.Repeat("0", 64), 1)
+	attach(t, store, subject, "application/vnd.in-toto+json", []byte(strings.Replace(other, "mycorp/cart", "evil/cart", 1)))
+
+	att, err := bundle.DiscoverAttestations(context.Background(), store, subject)
+	require.NoError(t, err)
+	assert.Equal(t, "spdx", att.SBOMFormat)
+	assert.Equal(t, 3, att.PackageCount)
+	assert.Equal(t, "https://github.com/actions/runner/github-hosted", att.BuilderID)
+	assert.Equal(t, "https://github.com/mycorp/cart", att.SourceRepo)
+	assert.Equal(t, "abc123", att.SourceCommit)
+
+	// The envelope is kept for the caller to verify
+	require.Len(t, att.Provenance, 1)
+	env := att.Provenance[0].Envelope
+	require.NotNil(t, env)
+	assert.Equal(t, "application/vnd.in-toto+json", env.PayloadType)
+	assert.Equal(t, statement, string(env.Payload))
+	assert.Equal(t, [][]byte{[]byte("signature")}, env.Signatures)
+}
+
+func TestDiscoverAttestationsNone(t *testing.T) {
+	store := memory.New()
+	layer := pushBytes(t, store, ocispec.MediaTypeImageLayerGzip, []byte("bundle"))
+	subject := pushManifest(t, store, ocispec.Manifest{Layers: []ocispec.Descriptor{layer}})
+
+	att, err := bundle.DiscoverAttestations(context.Background(), store, subject)
+	require.NoError(t, err)
+	assert.Empty(t, att.SBOMFormat)
+	assert.Empty(t, att.Provenance)
+}
@@ -0,0 +1,336 @@
+// File: pkg/bundle/cache.go
+package bundle
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// cacheLog logs cache maintenance, which runs outside any reconcile.
+var cacheLog = log.Log.WithName("blobcache")
+
+// BlobCache is a persistent, content-addressable cache of extracted bundle
+// layers keyed by blob digest. It lives on the operator's volume so that an
+// unchanged digest skips both the network fetch and the re-extraction, across
+// reconciles and restarts. Once the cache grows past its size limit, entries
+// are evicted least-recently-used first.
+//
+// Layout: <dir>/<algorithm>/<encoded digest>/ holds an extracted layer and
+// <dir>/.tmp/ holds in-progress downloads.
+type BlobCache struct {
+	dir      string
+	maxBytes int64
+	// workspaces, when set, has cache misses charged against its disk limit
+	// while they fetch and extract, as a workspace's fetch would be.
+	workspaces *WorkspaceManager
+
+	mu      sync.Mutex
+	lru     *list.List // front is most recently used
+	entries map[digest.Digest]*list.Element
+	size    int64
+	// loading holds a lock per digest being fetched, so the same digest is
+	// never fetched twice concurrently while different digests load in
+	// parallel.
+	loading map[digest.Digest]*loadLock
+}
+
+type loadLock struct {
+	sync.Mutex
+	waiters int
+}
+
+type cacheEntry struct {
+	digest digest.Digest
+	size   int64
+	refs   int
+}
+
+// CachedBundle is an extracted layer checked out of a BlobCache. It is pinned
+// against eviction until Release is called.
+type CachedBundle struct {
+	Digest digest.Digest
+	Path   string
+	// Hit reports whether the layer was already in the cache.
+	Hit bool
+
+	cache *BlobCache
+	once  sync.Once
+}
+
+// Release unpins the bundle so it may be evicted.
+func (b *CachedBundle) Release() {
+	b.once.Do(func() { b.cache.release(b.Digest) })
+}
+
+// NewBlobCache opens (or creates) a cache rooted at dir, bounded to maxBytes
+// of extracted content. A maxBytes of zero or less disables eviction.
+func NewBlobCache(dir string, maxBytes int64) (*BlobCache, error) {
+	c := &BlobCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[digest.Digest]*list.Element{},
+		loading:  map[digest.Digest]*loadLock{},
+	}
+	if err := os.RemoveAll(c.tmpDir()); err != nil {
+		return nil, fmt.Errorf("failed to clear cache temp dir: %w", err)
+	}
+	if err := os.MkdirAll(c.tmpDir(), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	if err := c.load(); err != nil {
+		return nil, fmt.Errorf("failed to load cache index: %w", err)
+	}
+	c.mu.Lock()
+	c.evictLocked()
+	c.mu.Unlock()
+	return c, nil
+}
+
+// ChargeLoadsTo makes cache misses count against the disk limit of m while
+// they download and extract, so enabling the cache does not lift the limit.
+func (c *BlobCache) ChargeLoadsTo(m *WorkspaceManager) {
+	c.workspaces = m
+}
+
+// Load returns the extracted contents of the tar.gz blob described by desc,
+// fetching it from fetcher on a cache miss.
+func (c *BlobCache) Load(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*CachedBundle, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid blob digest: %w", err)
+	}
+	if b := c.checkout(desc.Digest); b != nil {
+		b.Hit = true
+		return b, nil
+	}
+
+	unlock := c.lockDigest(desc.Digest)
+	defer unlock()
+	if b := c.checkout(desc.Digest); b != nil {
+		b.Hit = true
+		return b, nil
+	}
+
+	log.FromContext(ctx).V(1).Info("Cache miss, fetching", "digest", desc.Digest)
+	tmp, err := os.MkdirTemp(c.tmpDir(), "load-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	q := c.workspaces.newQuota()
+	defer q.release()
+
+	tarballPath := filepath.Join(tmp, "bundle.tar.gz")
+	if err := fetchBlobToFile(ctx, fetcher, desc, tarballPath, q); err != nil {
+		return nil, err
+	}
+	extractDir := filepath.Join(tmp, "extract")
+	if err := os.Mkdir(extractDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create extract dir: %w", err)
+	}
+	if err := extractTarballInto(ctx, tarballPath, extractDir, q); err != nil {
+		return nil, err
+	}
+	size, err := dirSize(extractDir)
+	if err != nil {
+		return nil, err
+	}
+
+	finalPath := c.entryPath(desc.Digest)
+	if err := os.MkdirAll(filepath.Dir(finalPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	if err := os.Rename(extractDir, finalPath); err != nil {
+		return nil, fmt.Errorf("failed to commit cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[desc.Digest] = c.lru.PushFront(&cacheEntry{digest: desc.Digest, size: size, refs: 1})
+	c.size += size
+	c.evictLocked()
+	return &CachedBundle{Digest: desc.Digest, Path: finalPath, cache: c}, nil
+}
+
+// Size returns the total size in bytes of all cached entries.
+func (c *BlobCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// checkout pins and returns the cached entry for dgst, or nil on a miss.
+func (c *BlobCache) checkout(dgst digest.Digest) *CachedBundle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[dgst]
+	if !ok {
+		return nil
+	}
+	el.Value.(*cacheEntry).refs++
+	c.lru.MoveToFront(el)
+
+	// Persist recency so LRU order survives restarts.
+	now := time.Now()
+	_ = os.Chtimes(c.entryPath(dgst), now, now)
+	return &CachedBundle{Digest: dgst, Path: c.entryPath(dgst), cache: c}
+}
+
+// lockDigest blocks until no other load of dgst is in progress and returns
+// the function that ends this one.
+func (c *BlobCache) lockDigest(dgst digest.Digest) func() {
+	c.mu.Lock()
+	l, ok := c.loading[dgst]
+	if !ok {
+		l = &loadLock{}
+		c.loading[dgst] = l
+	}
+	l.waiters++
+	c.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		c.mu.Lock()
+		if l.waiters--; l.waiters == 0 {
+			delete(c.loading, dgst)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *BlobCache) release(dgst digest.Digest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[dgst]; ok {
+		el.Value.(*cacheEntry).refs--
+	}
+	c.evictLocked()
+}
+
+// evictLocked removes least-recently-used entries that are not checked out
+// until the cache fits within maxBytes. c.mu must be held.
+func (c *BlobCache) evictLocked() {
+	if c.maxBytes <= 0 {
+		return
+	}
+	for el := c.lru.Back(); el != nil && c.size > c.maxBytes; {
+		prev := el.Prev()
+		e := el.Value.(*cacheEntry)
+		if e.refs == 0 {
+			if err := os.RemoveAll(c.entryPath(e.digest)); err != nil {
+				cacheLog.Error(err, "Failed to evict cache entry", "digest", e.digest)
+			} else {
+				cacheLog.Info("Evicted cache entry", "digest", e.digest, "bytes", e.size)
+				c.lru.Remove(el)
+				delete(c.entries, e.digest)
+				c.size -= e.size
+			}
+		}
+		el = prev
+	}
+}
+
+// load rebuilds the in-memory index from disk, ordering entries by their
+// last-used time.
+func (c *BlobCache) load() error {
+	type found struct {
+		entry   *cacheEntry
+		modTime time.Time
+	}
+	var all []found
+
+	algDirs, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, algDir := range algDirs {
+		if !algDir.IsDir() || algDir.Name() == filepath.Base(c.tmpDir()) {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(c.dir, algDir.Name()))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			dgst := digest.NewDigestFromEncoded(digest.Algorithm(algDir.Name()), e.Name())
+			path := filepath.Join(c.dir, algDir.Name(), e.Name())
+			if !e.IsDir() || dgst.Validate() != nil {
+				cacheLog.Info("Removing unrecognised cache entry", "path", path)
+				if err := os.RemoveAll(path); err != nil {
+					return err
+				}
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				return err
+			}
+			size, err := dirSize(path)
+			if err != nil {
+				return err
+			}
+			all = append(all, found{entry: &cacheEntry{digest: dgst, size: size}, modTime: info.ModTime()})
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].modTime.After(all[j].modTime) })
+	for _, f := range all {
+		c.entries[f.entry.digest] = c.lru.PushBack(f.entry)
+		c.size += f.entry.size
+	}
+	return nil
+}
+
+func (c *BlobCache) entryPath(dgst digest.Digest) string {
+	return filepath.Join(c.dir, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+func (c *BlobCache) tmpDir() string {
+	return filepath.Join(c.dir, ".tmp")
+}
+
+// dirSize returns the total size of the regular files under root.
+func dirSize(root string) (int64, error) {
+	stats, err := Stats(root)
+	return stats.Bytes, err
+}
+
+// BundleStats summarizes the files of an extracted bundle.
+type BundleStats struct {
+	Files int
+	Bytes int64
+}
+
+// Stats counts the regular files under root and their total size.
+func Stats(root string) (BundleStats, error) {
+	var stats BundleStats
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.Files++
+		stats.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return BundleStats{}, fmt.Errorf("failed to compute size of %s: %w", root, err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,219 @@
+// File: pkg/bundle/cache_test.go
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"mfe-operator/pkg/bundle"
+)
+
+func makeTarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func pushBlob(t *testing.T, store *memory.Store, data []byte) ocispec.Descriptor {
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, data)
+	require.NoError(t, store.Push(context.Background(), desc, bytes.NewReader(data)))
+	return desc
+}
+
+// countingFetcher records how many times each blob is fetched.
+type countingFetcher struct {
+	content.Fetcher
+	calls int
+}
+
+func (f *countingFetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	f.calls++
+	return f.Fetcher.Fetch(ctx, desc)
+}
+
+func TestBlobCacheHitSkipsFetch(t *testing.T) {
+	store := memory.New()
+	desc := pushBlob(t, store, makeTarball(t, map[string]string{"remoteEntry.js": "entry"}))
+	fetcher := &countingFetcher{Fetcher: store}
+
+	cache, err := bundle.NewBlobCache(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	first, err := cache.Load(context.Background(), fetcher, desc)
+	require.NoError(t, err)
+	first.Release()
+	second, err := cache.Load(context.Background(), fetcher, desc)
+	require.NoError(t, err)
+	defer second.Release()
+
+	assert.Equal(t, 1, fetcher.calls)
+	assert.False(t, first.Hit)
+	assert.True(t, second.Hit)
+	assert.Equal(t, first.Path, second.Path)
+	data, err := os.ReadFile(filepath.Join(second.Path, "remoteEntry.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "entry", string(data))
+
+	stats, err := bundle.Stats(second.Path)
+	require.NoError(t, err)
+	assert.Equal(t, bundle.BundleStats{Files: 1, Bytes: 5}, stats)
+}
+
+func TestBlobCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	store := memory.New()
+	a := pushBlob(t, store, makeTarball(t, map[string]string{"a.js": "aaaaaaaaaa"}))
+	b := pushBlob(t, store, makeTarball(t, map[string]string{"b.js": "bbbbbbbbbb"}))
+	c := pushBlob(t, store, makeTarball(t, map[string]string{"c.js": "cccccccccc"}))
+
+	// Room for two 10-byte entries.
+	cache, err := bundle.NewBlobCache(t.TempDir(), 20)
+	require.NoError(t, err)
+
+	for _, desc := range []ocispec.Descriptor{a, b, a, c} {
+		got, err := cache.Load(context.Background(), store, desc)
+		require.NoError(t, err)
+		got.Release()
+	}
+
+	assert.Equal(t, int64(20), cache.Size())
+	fetcher := &countingFetcher{Fetcher: store}
+	for _, desc := range []ocispec.Descriptor{a, c} {
+		got, err := cache.Load(context.Background(), fetcher, desc)
+		require.NoError(t, err)
+		got.Release()
+	}
+	assert.Equal(t, 0, fetcher.calls, "a and c should still be cached, b evicted")
+}
+
+func TestBlobCacheReloadsIndexFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	store := memory.New()
+	desc := pushBlob(t, store, makeTarball(t, map[string]string{"remoteEntry.js": "entry"}))
+
+	cache, err := bundle.NewBlobCache(dir, 0)
+	require.NoError(t, err)
+	got, err := cache.Load(context.Background(), store, desc)
+	require.NoError(t, err)
+	got.Release()
+
+	reopened, err := bundle.NewBlobCache(dir, 0)
+	require.NoError(t, err)
+	assert.Equal(t, cache.Size(), reopened.Size())
+
+	fetcher := &countingFetcher{Fetcher: store}
+	got, err = reopened.Load(context.Background(), fetcher, desc)
+	require.NoError(t, err)
+	got.Release()
+	assert.Equal(t, 0, fetcher.calls)
+}
+
+func TestBlobCacheRejectsInvalidBundle(t *testing.T) {
+	store := memory.New()
+	desc := pushBlob(t, store, []byte("not a tarball"))
+
+	cache, err := bundle.NewBlobCache(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	_, err = cache.Load(context.Background(), store, desc)
+	assert.ErrorIs(t, err, bundle.ErrInvalidBundle)
+	assert.Zero(t, cache.Size())
+}
+
+// blockingFetcher holds fetches of one blob until released.
+type blockingFetcher struct {
+	content.Fetcher
+	blocked ocispec.Descriptor
+	started chan struct{}
+	release chan struct{}
+	calls   int32
+}
+
+func (f *blockingFetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	if desc.Digest == f.blocked.Digest {
+		atomic.AddInt32(&f.calls, 1)
+		f.started <- struct{}{}
+		<-f.release
+	}
+	return f.Fetcher.Fetch(ctx, desc)
+}
+
+func TestBlobCacheLoadsDigestsIndependently(t *testing.T) {
+	store := memory.New()
+	slow := pushBlob(t, store, makeTarball(t, map[string]string{"remoteEntry.js": "slow"}))
+	fast := pushBlob(t, store, makeTarball(t, map[string]string{"remoteEntry.js": "fast"}))
+	fetcher := &blockingFetcher{Fetcher: store, blocked: slow, started: make(chan struct{}, 2), release: make(chan struct{})}
+	cache, err := bundle.NewBlobCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Two loads of the slow digest share a single fetch
+	var wg sync.WaitGroup
+	loaded := make([]*bundle.CachedBundle, 2)
+	for i := range loaded {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			b, err := cache.Load(ctx, fetcher, slow)
+			assert.NoError(t, err)
+			loaded[i] = b
+		}(i)
+	}
+	<-fetcher.started
+
+	// Another digest loads while the slow one is still in flight
+	b, err := cache.Load(ctx, fetcher, fast)
+	require.NoError(t, err)
+	b.Release()
+
+	close(fetcher.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetcher.calls))
+	require.NotNil(t, loaded[0])
+	require.NotNil(t, loaded[1])
+	assert.NotEqual(t, loaded[0].Hit, loaded[1].Hit)
+	loaded[0].Release()
+	loaded[1].Release()
+}
+
+func TestBlobCacheLoadsChargedToWorkspaces(t *testing.T) {
+	workspaces, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.UseUUID, 1024)
+	require.NoError(t, err)
+	cache, err := bundle.NewBlobCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	cache.ChargeLoadsTo(workspaces)
+
+	store := memory.New()
+	big := pushBlob(t, store, makeTarball(t, map[string]string{"big.js": strings.Repeat("x", 2048)}))
+	_, err = cache.Load(context.Background(), store, big)
+	assert.True(t, errors.Is(err, bundle.ErrWorkspaceFull))
+
+	// The failed load returned its bytes to the budget
+	small := pushBlob(t, store, makeTarball(t, map[string]string{"small.js": "var small;"}))
+	got, err := cache.Load(context.Background(), store, small)
+	require.NoError(t, err)
+	got.Release()
+}
@@ -4,17 +4,20 @@ package cdn
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 )
 
 type AzureBlobUploader struct {
-	client     *azblob.Client
-	container  string
+	client    *azblob.Client
+	container string
 }
 
 func NewAzureBlobUploader(connectionString, container string) (*AzureBlobUploader, error) {
@@ -39,8 +42,8 @@ func (u *AzureBlobUploader) Upload(ctx context.Context, localPath, remotePath st
 	blobPath = strings.TrimLeft(blobPath, "/")
 
 	_, err = u.client.UploadFile(ctx, u.container, blobPath, file, &azblob.UploadFileOptions{
-		HTTPHeaders: &azblob.HTTPHeaders{
-			BlobContentType: to.Ptr("application/octet-stream"),
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: to.Ptr(contentType(blobPath)),
 		},
 	})
 	if err != nil {
@@ -48,3 +51,39 @@ func (u *AzureBlobUploader) Upload(ctx context.Context, localPath, remotePath st
 	}
 	return nil
 }
+
+func (u *AzureBlobUploader) Download(ctx context.Context, remotePath string) ([]byte, error) {
+	blobPath := strings.TrimLeft(filepath.ToSlash(remotePath), "/")
+	resp, err := u.client.DownloadStream(ctx, u.container, blobPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from Azure Blob Storage: %w", err)
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (u *AzureBlobUploader) Delete(ctx context.Context, remotePath string) error {
+	blobPath := strings.TrimLeft(filepath.ToSlash(remotePath), "/")
+	_, err := u.client.DeleteBlob(ctx, u.container, blobPath, nil)
+	if err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return fmt.Errorf("failed to delete from Azure Blob Storage: %w", err)
+	}
+	return nil
+}
+
+func (u *AzureBlobUploader) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	pager := u.client.NewListBlobsFlatPager(u.container, &azblob.ListBlobsFlatOptions{
+		Prefix: to.Ptr(strings.TrimLeft(listPrefix(prefix), "/")),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure blobs: %w", err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			names = append(names, *item.Name)
+		}
+	}
+	return names, nil
+}
@@ -2,7 +2,6 @@
 package cdn_test
 
 import (
-	"cloud.google.com/go/storage"
 	"context"
 	"errors"
 	"fmt"
@@ -13,7 +12,6 @@ import (
 	"testing"
 	"time"
 
-	azblob "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/aws/aws-sdk-go/aws"
@@ -81,10 +79,7 @@ func TestS3UploaderIntegration(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	uploader := &cdn.S3Uploader{
-		client: s3.New(sess),
-		bucket: bucket,
-	}
+	uploader := cdn.NewS3UploaderWithClient(s3.New(sess), bucket)
 	tempFile := createTempFile(t)
 	err = uploader.Upload(context.Background(), tempFile, fmt.Sprintf("test/%d/file.txt", time.Now().UnixNano()))
 	assert.NoError(t, err)
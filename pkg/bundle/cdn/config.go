@@ -0,0 +1,136 @@
+// File: pkg/bundle/cdn/config.go
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// BackendConfig describes one CDN backend the operator can publish to.
+// Credentials come from the environment: AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY for S3, application default credentials for GCS and
+// the variable named by ConnectionStringEnv for Azure.
+type BackendConfig struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"` // s3, gcs, azure or file
+	Bucket   string `json:"bucket"`   // bucket, container for Azure or directory for file
+	Region   string `json:"region,omitempty"`
+
+	// PublicURL is the base URL the backend's files are served from, e.g.
+	// "https://cdn.example.com".
+	PublicURL string `json:"publicURL,omitempty"`
+
+	// ConnectionStringEnv names the environment variable holding the Azure
+	// storage connection string. Defaults to AZURE_STORAGE_CONNECTION_STRING.
+	ConnectionStringEnv string `json:"connectionStringEnv,omitempty"`
+}
+
+// Config is the operator's CDN configuration file.
+type Config struct {
+	Backends []BackendConfig `json:"backends"`
+}
+
+// Backend is a configured CDN backend.
+type Backend struct {
+	Client CDNClient
+	// PublicURL is the base URL files are served from; empty if unknown.
+	PublicURL string
+}
+
+// URL returns the public URL of remotePath, or "" if the backend has no
+// public URL.
+func (b Backend) URL(remotePath string) string {
+	if b.PublicURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(b.PublicURL, "/") + "/" + strings.TrimPrefix(remotePath, "/")
+}
+
+// Backends maps backend names to backends. A CDN target has the form
+// "<backend>/<path prefix>".
+type Backends map[string]Backend
+
+// LoadConfig reads a YAML or JSON CDN configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CDN config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse CDN config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// NewBackends creates a client for every backend in cfg.
+func NewBackends(ctx context.Context, cfg *Config) (Backends, error) {
+	backends := Backends{}
+	for _, b := range cfg.Backends {
+		if b.Name == "" || strings.Contains(b.Name, "/") {
+			return nil, fmt.Errorf("invalid CDN backend name %q", b.Name)
+		}
+		if _, dup := backends[b.Name]; dup {
+			return nil, fmt.Errorf("duplicate CDN backend %q", b.Name)
+		}
+		client, err := newClient(ctx, b)
+		if err != nil {
+			return nil, fmt.Errorf("CDN backend %s: %w", b.Name, err)
+		}
+		backends[b.Name] = Backend{Client: client, PublicURL: b.PublicURL}
+	}
+	return backends, nil
+}
+
+func newClient(ctx context.Context, b BackendConfig) (CDNClient, error) {
+	switch b.Provider {
+	case "s3":
+		return NewS3Uploader(b.Region, b.Bucket, os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	case "gcs":
+		return NewGCSUploader(ctx, b.Bucket)
+	case "azure":
+		env := b.ConnectionStringEnv
+		if env == "" {
+			env = "AZURE_STORAGE_CONNECTION_STRING"
+		}
+		return NewAzureBlobUploader(os.Getenv(env), b.Bucket)
+	case "file":
+		return NewFileUploader(b.Bucket)
+	default:
+		return nil, fmt.Errorf("unknown provider %q", b.Provider)
+	}
+}
+
+// SplitTarget splits a CDN target into its backend name and path prefix.
+// The prefix may not contain "." or ".." segments, which could resolve to
+// the backend root or to another target.
+func SplitTarget(target string) (backend, prefix string, err error) {
+	backend, prefix, _ = strings.Cut(target, "/")
+	prefix = strings.Trim(prefix, "/")
+	if backend == "" || prefix == "" {
+		return "", "", fmt.Errorf("CDN target %q must have the form <backend>/<path>", target)
+	}
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment == "." || segment == ".." {
+			return "", "", fmt.Errorf("CDN target %q must not contain %q path segments", target, segment)
+		}
+	}
+	return backend, prefix, nil
+}
+
+// Resolve returns the backend and path prefix for a CDN target.
+func (b Backends) Resolve(target string) (Backend, string, error) {
+	name, prefix, err := SplitTarget(target)
+	if err != nil {
+		return Backend{}, "", err
+	}
+	backend, ok := b[name]
+	if !ok {
+		return Backend{}, "", fmt.Errorf("unknown CDN backend %q in target %q", name, target)
+	}
+	return backend, prefix, nil
+}
@@ -0,0 +1,65 @@
+// File: pkg/bundle/cdn/delete.go
+package cdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrDeleteUnsupported is returned when a CDN client cannot remove files.
+var ErrDeleteUnsupported = errors.New("CDN client does not support deletes")
+
+// ErrListUnsupported is returned when a CDN client cannot enumerate files.
+var ErrListUnsupported = errors.New("CDN client does not support listing")
+
+// DeleteFiles removes files, given relative to prefix, from the CDN.
+// Files that are already gone are not an error.
+func DeleteFiles(ctx context.Context, client CDNClient, prefix string, files []string) error {
+	d, ok := client.(Deleter)
+	if !ok {
+		return ErrDeleteUnsupported
+	}
+	for _, file := range files {
+		remotePath := path.Join(prefix, file)
+		log.FromContext(ctx).V(1).Info("Deleting", "path", remotePath)
+		if err := d.Delete(ctx, remotePath); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", remotePath, err)
+		}
+	}
+	return nil
+}
+
+// ListFiles returns the files published under prefix, relative to it.
+func ListFiles(ctx context.Context, client CDNClient, prefix string) ([]string, error) {
+	l, ok := client.(Lister)
+	if !ok {
+		return nil, ErrListUnsupported
+	}
+	names, err := l.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	root := listPrefix(prefix)
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		if file, ok := strings.CutPrefix(strings.TrimLeft(name, "/"), root); ok && file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// listPrefix returns prefix as a directory, so that listing "app" does not
+// match "app2".
+func listPrefix(prefix string) string {
+	p := strings.Trim(path.Clean("/"+prefix), "/")
+	if p == "" {
+		return ""
+	}
+	return p + "/"
+}
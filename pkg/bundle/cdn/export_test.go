@@ -0,0 +1,16 @@
+package cdn
+
+import (
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// NewS3UploaderWithClient and NewGCSUploaderWithClient build uploaders
+// around clients pointed at test servers.
+func NewS3UploaderWithClient(client *s3.S3, bucket string) *S3Uploader {
+	return &S3Uploader{client: client, bucket: bucket}
+}
+
+func NewGCSUploaderWithClient(client *storage.Client, bucket string) *GCSUploader {
+	return &GCSUploader{client: client, bucketName: bucket}
+}
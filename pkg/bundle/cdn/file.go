@@ -0,0 +1,124 @@
+// File: pkg/bundle/cdn/file.go
+package cdn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileUploader publishes to a local directory, e.g. one served by a web
+// server in development or tests.
+type FileUploader struct {
+	root string
+}
+
+func NewFileUploader(root string) (*FileUploader, error) {
+	if root == "" {
+		return nil, errors.New("file backend needs a directory")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", root, err)
+	}
+	return &FileUploader{root: root}, nil
+}
+
+// path maps remotePath into the root directory, refusing paths that would
+// escape it.
+func (u *FileUploader) path(remotePath string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(remotePath, "/")))
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid remote path %q", remotePath)
+	}
+	return filepath.Join(u.root, rel), nil
+}
+
+func (u *FileUploader) Upload(ctx context.Context, localPath, remotePath string) error {
+	dst, err := u.path(remotePath)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", localPath, err)
+	}
+	defer src.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", remotePath, err)
+	}
+
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", remotePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", remotePath, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", remotePath, err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to write %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+func (u *FileUploader) Download(ctx context.Context, remotePath string) ([]byte, error) {
+	p, err := u.path(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", remotePath, err)
+	}
+	return data, nil
+}
+
+func (u *FileUploader) Delete(ctx context.Context, remotePath string) error {
+	p, err := u.path(remotePath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", remotePath, err)
+	}
+	return nil
+}
+
+func (u *FileUploader) List(ctx context.Context, prefix string) ([]string, error) {
+	dir, err := u.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(u.root, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+	return names, nil
+}
@@ -4,10 +4,13 @@ package cdn
 import (
 	"cloud.google.com/go/storage"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+
+	"google.golang.org/api/iterator"
 )
 
 type GCSUploader struct {
@@ -35,6 +38,7 @@ func (u *GCSUploader) Upload(ctx context.Context, localPath, remotePath string)
 
 	remotePath = filepath.ToSlash(remotePath)
 	w := u.client.Bucket(u.bucketName).Object(remotePath).NewWriter(ctx)
+	w.ContentType = contentType(remotePath)
 	defer w.Close()
 
 	if _, err := io.Copy(w, f); err != nil {
@@ -42,3 +46,35 @@ func (u *GCSUploader) Upload(ctx context.Context, localPath, remotePath string)
 	}
 	return nil
 }
+
+func (u *GCSUploader) Download(ctx context.Context, remotePath string) ([]byte, error) {
+	r, err := u.client.Bucket(u.bucketName).Object(filepath.ToSlash(remotePath)).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from GCS: %w", err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (u *GCSUploader) Delete(ctx context.Context, remotePath string) error {
+	err := u.client.Bucket(u.bucketName).Object(filepath.ToSlash(remotePath)).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete from GCS: %w", err)
+	}
+	return nil
+}
+
+func (u *GCSUploader) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	it := u.client.Bucket(u.bucketName).Objects(ctx, &storage.Query{Prefix: listPrefix(prefix)})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GCS objects: %w", err)
+		}
+		names = append(names, attrs.Name)
+	}
+}
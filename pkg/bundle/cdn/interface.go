@@ -1,9 +1,38 @@
 // File: pkg/bundle/cdn/interface.go
 package cdn
 
-import "context"
+import (
+	"context"
+	"mime"
+	"path"
+)
 
 // CDNClient defines an interface for uploading files to a CDN
 type CDNClient interface {
 	Upload(ctx context.Context, localPath, remotePath string) error
 }
+
+// contentType returns the Content-Type a file is stored with, derived from
+// the extension of its remote path. Browsers refuse scripts and styles served
+// as anything else, and the smoke check requires the same type.
+func contentType(remotePath string) string {
+	if t := mime.TypeByExtension(path.Ext(remotePath)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// Downloader is implemented by CDN clients that can read back published files
+type Downloader interface {
+	Download(ctx context.Context, remotePath string) ([]byte, error)
+}
+
+// Deleter is implemented by CDN clients that can remove published files
+type Deleter interface {
+	Delete(ctx context.Context, remotePath string) error
+}
+
+// Lister is implemented by CDN clients that can enumerate published files
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]string, error)
+}
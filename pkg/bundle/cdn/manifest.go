@@ -0,0 +1,69 @@
+// File: pkg/bundle/cdn/manifest.go
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+)
+
+// ManifestFile is the name of the deployment manifest written under every
+// published prefix.
+const ManifestFile = ".mfe-manifest.json"
+
+// ErrDownloadUnsupported is returned when a CDN client cannot read files back.
+var ErrDownloadUnsupported = errors.New("CDN client does not support downloads")
+
+// Manifest records what was published under a CDN prefix.
+type Manifest struct {
+	// Digest is the OCI manifest digest of the published bundle.
+	Digest      string `json:"digest"`
+	PublishedAt string `json:"publishedAt"`
+}
+
+// PublishManifest writes m to <prefix>/.mfe-manifest.json.
+func PublishManifest(ctx context.Context, client CDNClient, prefix string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return UploadBytes(ctx, client, data, path.Join(prefix, ManifestFile))
+}
+
+// FetchManifest reads back the deployment manifest under prefix.
+func FetchManifest(ctx context.Context, client CDNClient, prefix string) (*Manifest, error) {
+	d, ok := client.(Downloader)
+	if !ok {
+		return nil, ErrDownloadUnsupported
+	}
+	data, err := d.Download(ctx, path.Join(prefix, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode CDN manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// UploadBytes uploads in-memory content through a temporary file, since
+// CDNClient only uploads from disk.
+func UploadBytes(ctx context.Context, client CDNClient, data []byte, remotePath string) error {
+	f, err := os.CreateTemp("", "mfe-upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return client.Upload(ctx, f.Name(), remotePath)
+}
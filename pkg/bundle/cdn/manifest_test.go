@@ -0,0 +1,111 @@
+// File: pkg/bundle/cdn/manifest_test.go
+package cdn_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// memoryCDN is an in-memory CDN client that supports downloads and deletes.
+type memoryCDN map[string][]byte
+
+func (m memoryCDN) Upload(ctx context.Context, localPath, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	m[remotePath] = data
+	return nil
+}
+
+func (m memoryCDN) Download(ctx context.Context, remotePath string) ([]byte, error) {
+	data, ok := m[remotePath]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (m memoryCDN) Delete(ctx context.Context, remotePath string) error {
+	delete(m, remotePath)
+	return nil
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	client := memoryCDN{}
+	want := cdn.Manifest{Digest: "sha256:abc", PublishedAt: "2024-01-02T03:04:05Z"}
+	require.NoError(t, cdn.PublishManifest(context.Background(), client, "apps/checkout", want))
+	assert.Contains(t, client, "apps/checkout/.mfe-manifest.json")
+
+	got, err := cdn.FetchManifest(context.Background(), client, "apps/checkout")
+	require.NoError(t, err)
+	assert.Equal(t, want, *got)
+}
+
+func TestFetchManifestRequiresDownloader(t *testing.T) {
+	_, err := cdn.FetchManifest(context.Background(), new(MockCDNClient), "apps/checkout")
+	assert.True(t, errors.Is(err, cdn.ErrDownloadUnsupported))
+}
+
+func TestBackendsResolve(t *testing.T) {
+	backends := cdn.Backends{"primary": {Client: memoryCDN{}, PublicURL: "https://cdn.example.com/"}}
+
+	backend, prefix, err := backends.Resolve("primary/apps/checkout/")
+	require.NoError(t, err)
+	assert.Equal(t, "apps/checkout", prefix)
+	assert.Equal(t, "https://cdn.example.com/apps/checkout/remoteEntry.js", backend.URL(prefix+"/remoteEntry.js"))
+
+	_, _, err = backends.Resolve("secondary/apps/checkout")
+	assert.Error(t, err)
+	_, _, err = backends.Resolve("primary")
+	assert.Error(t, err)
+
+	// Dot segments could resolve to the backend root or another prefix
+	for _, target := range []string{"primary/.", "primary/apps/..", "primary/./apps", "primary/apps/../vendor"} {
+		_, _, err = backends.Resolve(target)
+		assert.Error(t, err, target)
+	}
+	_, prefix, err = backends.Resolve("primary/apps/.well-known/..checkout")
+	require.NoError(t, err)
+	assert.Equal(t, "apps/.well-known/..checkout", prefix)
+}
+
+func TestDeleteFiles(t *testing.T) {
+	client := memoryCDN{
+		"vendor/lodash@4.17.20/js/12.js":  []byte("lodash"),
+		"vendor/lodash@4.17.21/js/12.js":  []byte("lodash"),
+		"vendor/lodash@4.17.20/js/12.css": []byte("styles"),
+	}
+	require.NoError(t, cdn.DeleteFiles(context.Background(), client, "vendor/lodash@4.17.20", []string{"js/12.js", "js/12.css", "js/missing.js"}))
+	assert.Equal(t, memoryCDN{"vendor/lodash@4.17.21/js/12.js": []byte("lodash")}, client)
+
+	err := cdn.DeleteFiles(context.Background(), new(MockCDNClient), "vendor/lodash@4.17.20", []string{"js/12.js"})
+	assert.True(t, errors.Is(err, cdn.ErrDeleteUnsupported))
+}
+
+func TestListFiles(t *testing.T) {
+	ctx := context.Background()
+	files, err := cdn.NewFileUploader(t.TempDir())
+	require.NoError(t, err)
+	bundle := writeBundle(t, map[string]string{"remoteEntry.js": "entry", "js/1.js": "chunk"})
+	require.NoError(t, cdn.UploadDirectoryToCDN(ctx, files, bundle, "checkout/versions/aaa"))
+	require.NoError(t, cdn.UploadDirectoryToCDN(ctx, files, bundle, "checkout2"))
+
+	listed, err := cdn.ListFiles(ctx, files, "checkout/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"versions/aaa/remoteEntry.js", "versions/aaa/js/1.js"}, listed)
+
+	listed, err = cdn.ListFiles(ctx, files, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	_, err = cdn.ListFiles(ctx, new(MockCDNClient), "checkout")
+	assert.True(t, errors.Is(err, cdn.ErrListUnsupported))
+}
@@ -4,12 +4,13 @@ package cdn
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"io"
+	"os"
+	"path/filepath"
 )
 
 type S3Uploader struct {
@@ -45,12 +46,53 @@ func (u *S3Uploader) Upload(ctx context.Context, localPath, remotePath string) e
 	defer file.Close()
 
 	_, err = u.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(filepath.ToSlash(remotePath)),
+		Body:        file,
+		ContentType: aws.String(contentType(remotePath)),
+	})
+	if err != nil {
+		return fmt.Errorf("S3 upload failed: %w", err)
+	}
+	return nil
+}
+
+func (u *S3Uploader) Download(ctx context.Context, remotePath string) ([]byte, error) {
+	out, err := u.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(u.bucket),
 		Key:    aws.String(filepath.ToSlash(remotePath)),
-		Body:   file,
 	})
 	if err != nil {
-		return fmt.Errorf("S3 upload failed: %w", err)
+		return nil, fmt.Errorf("S3 download failed: %w", err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+func (u *S3Uploader) Delete(ctx context.Context, remotePath string) error {
+	_, err := u.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(u.bucket),
+		Key:    aws.String(filepath.ToSlash(remotePath)),
+	})
+	if err != nil {
+		return fmt.Errorf("S3 delete failed: %w", err)
 	}
 	return nil
 }
+
+func (u *S3Uploader) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := u.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(u.bucket),
+		Prefix: aws.String(listPrefix(prefix)),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("S3 list failed: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,159 @@
+// File: pkg/bundle/cdn/smoke.go
+package cdn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SmokeFile is a published file and the content it must be served with.
+type SmokeFile struct {
+	// Path is the remote path of the file.
+	Path string
+	// SHA256 is the "sha256:<hex>" digest of the file's content.
+	SHA256 string
+}
+
+// javascriptTypes are the media types browsers accept for scripts.
+var javascriptTypes = map[string]bool{
+	"text/javascript":          true,
+	"application/javascript":   true,
+	"application/x-javascript": true,
+}
+
+// maxSmokeCheckBody bounds how much of a response SmokeCheck reads; larger
+// bodies cannot be the published file and fail the check.
+const maxSmokeCheckBody = 32 << 20
+
+// SampleFiles picks the files of the bundle in dir to smoke check: the
+// entry point and up to chunks JavaScript and CSS files, spread evenly over
+// the sorted file list so that every publish of a bundle checks the same
+// files. Paths are returned as remote paths under prefix.
+func SampleFiles(dir, entry, prefix string, chunks int) ([]SmokeFile, error) {
+	var candidates []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch path.Ext(rel) {
+		case ".js", ".mjs", ".css":
+			if rel != entry {
+				candidates = append(candidates, rel)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundle files: %w", err)
+	}
+	sort.Strings(candidates)
+
+	sample := []string{entry}
+	if chunks > len(candidates) {
+		chunks = len(candidates)
+	}
+	for i := 0; i < chunks; i++ {
+		sample = append(sample, candidates[i*len(candidates)/chunks])
+	}
+
+	files := make([]SmokeFile, 0, len(sample))
+	for _, rel := range sample {
+		sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, SmokeFile{Path: path.Join(prefix, rel), SHA256: sum})
+	}
+	return files, nil
+}
+
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SmokeCheck fetches every file from baseURL and checks that it is served
+// with status 200, a Content-Type matching its extension and the expected
+// content. The error lists every file that failed.
+func SmokeCheck(ctx context.Context, client *http.Client, baseURL string, files []SmokeFile) error {
+	base := Backend{PublicURL: baseURL}
+	var failures []string
+	for _, f := range files {
+		if err := checkFile(ctx, client, base.URL(f.Path), f); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("smoke check failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func checkFile(ctx context.Context, client *http.Client, url string, f SmokeFile) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !contentTypeMatches(f.Path, ct) {
+		return fmt.Errorf("%s: unexpected Content-Type %q", url, ct)
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, io.LimitReader(resp.Body, maxSmokeCheckBody+1))
+	if err != nil {
+		return fmt.Errorf("%s: failed to read body: %w", url, err)
+	}
+	if n > maxSmokeCheckBody {
+		return fmt.Errorf("%s: body exceeds %d bytes", url, maxSmokeCheckBody)
+	}
+	if sum := "sha256:" + hex.EncodeToString(h.Sum(nil)); sum != f.SHA256 {
+		return fmt.Errorf("%s: content %s does not match published %s", url, sum, f.SHA256)
+	}
+	return nil
+}
+
+// contentTypeMatches reports whether contentType suits a file named name.
+// Files with an extension of unknown type match any Content-Type.
+func contentTypeMatches(name, contentType string) bool {
+	want := mime.TypeByExtension(path.Ext(name))
+	if want == "" {
+		return true
+	}
+	wantType, _, _ := mime.ParseMediaType(want)
+	gotType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return gotType == wantType || javascriptTypes[gotType] && javascriptTypes[wantType]
+}
@@ -0,0 +1,110 @@
+// File: pkg/bundle/cdn/smoke_test.go
+package cdn_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+func writeBundle(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(body), 0o644))
+	}
+	return dir
+}
+
+func TestSmokeCheck(t *testing.T) {
+	ctx := context.Background()
+	bundle := writeBundle(t, map[string]string{
+		"remoteEntry.js":   "var checkout;",
+		"js/101.a1b2.js":   "chunk 101",
+		"js/202.c3d4.js":   "chunk 202",
+		"js/303.e5f6.js":   "chunk 303",
+		"css/main.css":     "body{}",
+		"img/logo.svg":     "<svg/>",
+		"mf-manifest.json": "{}",
+	})
+	root := t.TempDir()
+	files, err := cdn.NewFileUploader(root)
+	require.NoError(t, err)
+	uploaded, err := cdn.UploadDirectory(ctx, files, bundle, "apps/checkout")
+	require.NoError(t, err)
+	assert.Equal(t, cdn.UploadStats{Files: 7, Bytes: 54}, uploaded)
+	server := httptest.NewServer(http.FileServer(http.Dir(root)))
+	defer server.Close()
+
+	sample, err := cdn.SampleFiles(bundle, "remoteEntry.js", "apps/checkout", 2)
+	require.NoError(t, err)
+	require.Len(t, sample, 3)
+	assert.Equal(t, "apps/checkout/remoteEntry.js", sample[0].Path)
+	assert.Equal(t, "apps/checkout/css/main.css", sample[1].Path)
+	assert.Equal(t, "apps/checkout/js/202.c3d4.js", sample[2].Path)
+	require.NoError(t, cdn.SmokeCheck(ctx, server.Client(), server.URL, sample))
+
+	// Stale content and missing files both fail
+	require.NoError(t, os.WriteFile(filepath.Join(root, "apps/checkout/remoteEntry.js"), []byte("var stale;"), 0o644))
+	require.NoError(t, files.Delete(ctx, "apps/checkout/js/202.c3d4.js"))
+	err = cdn.SmokeCheck(ctx, server.Client(), server.URL, sample)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "remoteEntry.js: content sha256:")
+	assert.Contains(t, err.Error(), "202.c3d4.js: status 404")
+	assert.NotContains(t, err.Error(), "main.css")
+}
+
+func TestSmokeCheckContentType(t *testing.T) {
+	ctx := context.Background()
+	bundle := writeBundle(t, map[string]string{"remoteEntry.js": "var checkout;"})
+	sample, err := cdn.SampleFiles(bundle, "remoteEntry.js", "checkout", 3)
+	require.NoError(t, err)
+
+	contentType := "application/javascript"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte("var checkout;"))
+	}))
+	defer server.Close()
+
+	require.NoError(t, cdn.SmokeCheck(ctx, server.Client(), server.URL, sample))
+	contentType = "text/plain; charset=utf-8"
+	assert.ErrorContains(t, cdn.SmokeCheck(ctx, server.Client(), server.URL, sample), `unexpected Content-Type "text/plain; charset=utf-8"`)
+}
+
+func TestSmokeCheckBodyLimit(t *testing.T) {
+	ctx := context.Background()
+	bundle := writeBundle(t, map[string]string{"remoteEntry.js": "var checkout;"})
+	sample, err := cdn.SampleFiles(bundle, "remoteEntry.js", "checkout", 0)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript")
+		chunk := make([]byte, 1<<20)
+		for i := 0; i < 40; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	assert.ErrorContains(t, cdn.SmokeCheck(ctx, server.Client(), server.URL, sample), "body exceeds")
+}
+
+func TestFileUploaderRejectsEscapingPaths(t *testing.T) {
+	files, err := cdn.NewFileUploader(t.TempDir())
+	require.NoError(t, err)
+	local := filepath.Join(t.TempDir(), "f.js")
+	require.NoError(t, os.WriteFile(local, nil, 0o644))
+	assert.Error(t, files.Upload(context.Background(), local, "../outside.js"))
+}
@@ -3,14 +3,29 @@ package cdn
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// UploadStats summarizes an upload.
+type UploadStats struct {
+	Files int
+	Bytes int64
+}
+
 // UploadDirectoryToCDN walks a directory and uploads all files to the target CDN path.
 func UploadDirectoryToCDN(ctx context.Context, cdn CDNClient, srcDir, cdnBasePath string) error {
-	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+	_, err := UploadDirectory(ctx, cdn, srcDir, cdnBasePath)
+	return err
+}
+
+// UploadDirectory is UploadDirectoryToCDN, also reporting what was uploaded
+// before it returned.
+func UploadDirectory(ctx context.Context, cdn CDNClient, srcDir, cdnBasePath string) (UploadStats, error) {
+	var stats UploadStats
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -22,7 +37,13 @@ func UploadDirectoryToCDN(ctx context.Context, cdn CDNClient, srcDir, cdnBasePat
 			return err
 		}
 		cdnPath := filepath.ToSlash(filepath.Join(cdnBasePath, relPath))
-		fmt.Printf("Uploading %s -> %s\n", path, cdnPath)
-		return cdn.Upload(ctx, path, cdnPath)
+		log.FromContext(ctx).V(1).Info("Uploading", "path", cdnPath)
+		if err := cdn.Upload(ctx, path, cdnPath); err != nil {
+			return err
+		}
+		stats.Files++
+		stats.Bytes += info.Size()
+		return nil
 	})
+	return stats, err
 }
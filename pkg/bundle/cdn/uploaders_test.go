@@ -0,0 +1,111 @@
+// File: pkg/bundle/cdn/uploaders_test.go
+package cdn_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+
+	"mfe-operator/pkg/bundle/cdn"
+)
+
+// uploadServer records the Content-Type each uploaded object was given,
+// read by contentType from the upload request.
+type uploadServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	types []string
+}
+
+func newUploadServer(t *testing.T, status int, body string, contentType func(*http.Request) string) *uploadServer {
+	s := &uploadServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.types = append(s.types, contentType(r))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func uploadBundleFiles(t *testing.T, uploader cdn.CDNClient) {
+	dir := t.TempDir()
+	for _, name := range []string{"remoteEntry.js", "main.css", "logo.svg"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644))
+		require.NoError(t, uploader.Upload(context.Background(), filepath.Join(dir, name), "apps/checkout/"+name))
+	}
+}
+
+// mediaTypes strips parameters such as charset, which differ between
+// platforms' MIME tables.
+func mediaTypes(types []string) []string {
+	var stripped []string
+	for _, t := range types {
+		stripped = append(stripped, strings.TrimSpace(strings.Split(t, ";")[0]))
+	}
+	return stripped
+}
+
+var wantTypes = []string{"text/javascript", "text/css", "image/svg+xml"}
+
+func TestS3UploaderSetsContentType(t *testing.T) {
+	server := newUploadServer(t, http.StatusOK, "", func(r *http.Request) string { return r.Header.Get("Content-Type") })
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(server.URL),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+	})
+	require.NoError(t, err)
+
+	uploadBundleFiles(t, cdn.NewS3UploaderWithClient(s3.New(sess), "bundles"))
+	assert.Equal(t, wantTypes, mediaTypes(server.types))
+}
+
+// gcsContentType finds the object's contentType in the metadata part of a
+// multipart upload.
+var gcsContentType = regexp.MustCompile(`"contentType":\s*"([^"]*)"`)
+
+func TestGCSUploaderSetsContentType(t *testing.T) {
+	server := newUploadServer(t, http.StatusOK, `{"bucket": "bundles", "name": "object"}`, func(r *http.Request) string {
+		body, _ := io.ReadAll(r.Body)
+		if m := gcsContentType.FindSubmatch(body); m != nil {
+			return string(m[1])
+		}
+		return ""
+	})
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(server.URL+"/storage/v1/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+
+	uploadBundleFiles(t, cdn.NewGCSUploaderWithClient(client, "bundles"))
+	assert.Equal(t, wantTypes, mediaTypes(server.types))
+}
+
+func TestAzureBlobUploaderSetsContentType(t *testing.T) {
+	server := newUploadServer(t, http.StatusCreated, "", func(r *http.Request) string { return r.Header.Get("x-ms-blob-content-type") })
+	uploader, err := cdn.NewAzureBlobUploader(
+		"DefaultEndpointsProtocol=http;AccountName=test;AccountKey=dGVzdA==;BlobEndpoint="+server.URL+"/test;", "bundles")
+	require.NoError(t, err)
+
+	uploadBundleFiles(t, uploader)
+	assert.Equal(t, wantTypes, mediaTypes(server.types))
+}
@@ -9,6 +9,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // ExtractTarball extracts the given tar.gz file using the desired strategy.
@@ -18,17 +20,27 @@ func ExtractTarball(ctx context.Context, tarballPath, baseOutputPath, crName str
 		return "", err
 	}
 
-	fmt.Printf("Extracting tarball: %s to directory: %s\n", tarballPath, destDir)
+	if err := extractTarballInto(ctx, tarballPath, destDir, nil); err != nil {
+		return "", err
+	}
+	return destDir, nil
+}
+
+// extractTarballInto extracts the given tar.gz file into an existing
+// directory, charging what it writes to q.
+func extractTarballInto(ctx context.Context, tarballPath, destDir string, q *quota) error {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("Extracting tarball", "path", tarballPath, "dir", destDir)
 
 	f, err := os.Open(tarballPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open tarball: %w", err)
+		return fmt.Errorf("failed to open tarball: %w", err)
 	}
 	defer f.Close()
 
 	gzReader, err := gzip.NewReader(f)
 	if err != nil {
-		return "", fmt.Errorf("failed to create gzip reader: %w", err)
+		return fmt.Errorf("%w: failed to create gzip reader: %w", ErrInvalidBundle, err)
 	}
 	defer gzReader.Close()
 
@@ -40,39 +52,37 @@ func ExtractTarball(ctx context.Context, tarballPath, baseOutputPath, crName str
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("error reading tar: %w", err)
+			return fmt.Errorf("%w: error reading tar: %w", ErrInvalidBundle, err)
 		}
 
 		targetPath := filepath.Join(destDir, hdr.Name)
 		if err := ensureValidPath(destDir, targetPath); err != nil {
-			return "", fmt.Errorf("invalid tar entry path: %w", err)
+			return fmt.Errorf("%w: invalid tar entry path: %w", ErrInvalidBundle, err)
 		}
 
 		switch hdr.Typeflag {
 		case tar.TypeDir:
 			if err := os.MkdirAll(targetPath, 0o755); err != nil {
-				return "", fmt.Errorf("failed to create directory: %w", err)
+				return fmt.Errorf("failed to create directory: %w", err)
 			}
 		case tar.TypeReg:
 			if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
-				return "", fmt.Errorf("failed to create parent directory: %w", err)
+				return fmt.Errorf("failed to create parent directory: %w", err)
 			}
 			outFile, err := os.Create(targetPath)
 			if err != nil {
-				return "", fmt.Errorf("failed to create file: %w", err)
+				return fmt.Errorf("failed to create file: %w", err)
 			}
-			if _, err := io.Copy(outFile, tarReader); err != nil {
+			if _, err := io.Copy(q.writer(outFile), tarReader); err != nil {
 				outFile.Close()
-				return "", fmt.Errorf("failed to write file: %w", err)
+				return fmt.Errorf("failed to write file: %w", err)
 			}
 			outFile.Close()
 		default:
-			fmt.Printf("Skipping unsupported tar entry: %s\n", hdr.Name)
+			logger.Info("Skipping unsupported tar entry", "name", hdr.Name, "type", hdr.Typeflag)
 		}
 	}
-
-	fmt.Println("Extraction completed successfully.")
-	return destDir, nil
+	return nil
 }
 
 // ensureValidPath ensures no directory traversal is possible
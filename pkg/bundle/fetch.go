@@ -3,15 +3,23 @@ package bundle
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/registry/remote"
-	"oras.land/oras-go/v2"
-	"oras.land/oras-go/v2/content/oci"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// ErrInvalidBundle is returned when an artifact is not a usable bundle.
+// Retrying will not help until the artifact or its reference changes.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
 // FetchOCIArtifact downloads an OCI artifact to a local tarball using the given naming strategy.
 func FetchOCIArtifact(ctx context.Context, ref string, baseOutputPath, crName string, strategy TarballNamingStrategy) (string, error) {
 	outDir, err := ResolveOutputPath(strategy, baseOutputPath, crName, "fetch")
@@ -20,45 +28,91 @@ func FetchOCIArtifact(ctx context.Context, ref string, baseOutputPath, crName st
 	}
 
 	filePath := filepath.Join(outDir, "bundle.tar.gz")
-	fmt.Printf("Fetching OCI artifact %s -> %s\n", ref, filePath)
+	log.FromContext(ctx).V(1).Info("Fetching OCI artifact", "ref", ref, "path", filePath)
 
-	target, err := os.Create(filePath)
+	art, err := ResolveArtifact(ctx, ref)
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return "", err
 	}
-	defer target.Close()
+	if err := fetchBlobToFile(ctx, art.Repository, art.Layer, filePath, nil); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
 
+// Artifact is a bundle artifact resolved against its registry.
+type Artifact struct {
+	Repository *remote.Repository
+	// Manifest is the descriptor that signatures and attestations refer to.
+	Manifest ocispec.Descriptor
+	// Layer is the bundle tarball.
+	Layer ocispec.Descriptor
+}
+
+// ResolveManifest resolves ref to its manifest descriptor. For a tag this is
+// a single HEAD request against the registry.
+func ResolveManifest(ctx context.Context, ref string) (*remote.Repository, ocispec.Descriptor, error) {
 	repo, err := remote.NewRepository(ref)
 	if err != nil {
-		return "", fmt.Errorf("failed to create remote repository: %w", err)
+		return nil, ocispec.Descriptor{}, fmt.Errorf("failed to create remote repository: %w", err)
 	}
+	desc, err := repo.Resolve(ctx, repo.Reference.Reference)
+	if err != nil {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return repo, desc, nil
+}
 
-	tmpDir, err := os.MkdirTemp("", "mfe-oci-pull-*")
+// ResolveArtifact resolves ref against its registry and locates the bundle
+// tarball layer in its manifest.
+func ResolveArtifact(ctx context.Context, ref string) (*Artifact, error) {
+	repo, desc, err := ResolveManifest(ctx, ref)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp dir: %w", err)
+		return nil, err
 	}
-	defer os.RemoveAll(tmpDir)
+	return NewArtifact(ctx, repo, desc)
+}
 
-	store, err := oci.NewWithTemp(tmpDir)
+// NewArtifact fetches the manifest described by manifestDesc and locates the
+// bundle tarball layer in it.
+func NewArtifact(ctx context.Context, repo *remote.Repository, manifestDesc ocispec.Descriptor) (*Artifact, error) {
+	data, err := content.FetchAll(ctx, repo, manifestDesc)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp store: %w", err)
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode manifest: %w", ErrInvalidBundle, err)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("%w: artifact %s has no layers", ErrInvalidBundle, manifestDesc.Digest)
 	}
 
-	desc, err := oras.Copy(ctx, repo, "latest", store, "latest", oras.DefaultCopyOptions)
+	// The bundle is the first layer; any further layers are ignored.
+	return &Artifact{Repository: repo, Manifest: manifestDesc, Layer: manifest.Layers[0]}, nil
+}
+
+// fetchBlobToFile streams the blob described by desc into filePath, verifying
+// its size and digest on the way and charging what it writes to q.
+func fetchBlobToFile(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, filePath string, q *quota) error {
+	target, err := os.Create(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to pull OCI artifact: %w", err)
+		return fmt.Errorf("failed to create file: %w", err)
 	}
+	defer target.Close()
 
-	blobReader, err := store.Fetch(ctx, desc)
+	blobReader, err := fetcher.Fetch(ctx, desc)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch blob: %w", err)
+		return fmt.Errorf("failed to fetch blob: %w", err)
 	}
 	defer blobReader.Close()
 
-	if _, err := io.Copy(target, blobReader); err != nil {
-		return "", fmt.Errorf("failed to write to file: %w", err)
+	verifier := content.NewVerifyReader(blobReader, desc)
+	if _, err := io.Copy(q.writer(target), verifier); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
 	}
-
-	fmt.Println("OCI fetch complete.")
-	return filePath, nil
+	if err := verifier.Verify(); err != nil {
+		return fmt.Errorf("failed to verify blob %s: %w", desc.Digest, err)
+	}
+	return nil
 }
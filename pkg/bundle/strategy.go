@@ -19,6 +19,30 @@ const (
 	UseUUID
 )
 
+var strategyNames = map[TarballNamingStrategy]string{
+	IsolatedTempDir: "IsolatedTempDir",
+	UseCRName:       "UseCRName",
+	UseUUID:         "UseUUID",
+}
+
+func (s TarballNamingStrategy) String() string {
+	if name, ok := strategyNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("TarballNamingStrategy(%d)", int(s))
+}
+
+// ParseTarballNamingStrategy parses a strategy name as used in operator flags
+// and the MicroFrontend spec.
+func ParseTarballNamingStrategy(name string) (TarballNamingStrategy, error) {
+	for s, n := range strategyNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return IsolatedTempDir, fmt.Errorf("unknown workspace strategy %q", name)
+}
+
 // Sanitize name to be safe for filenames and folder names
 func SanitizeName(name string) string {
 	re := regexp.MustCompile(`[^a-zA-Z0-9-_]`)
@@ -29,11 +53,9 @@ func SanitizeName(name string) string {
 func ResolveOutputPath(strategy TarballNamingStrategy, basePath, crName, prefix string) (string, error) {
 	switch strategy {
 	case IsolatedTempDir:
-		fmt.Printf("Using strategy: IsolatedTempDir for %s\n", prefix)
 		return os.MkdirTemp(basePath, fmt.Sprintf("mfe-%s-*", prefix))
 
 	case UseCRName:
-		fmt.Printf("Using strategy: UseCRName for %s\n", prefix)
 		sanitized := SanitizeName(crName)
 		if sanitized == "" {
 			return ResolveOutputPath(IsolatedTempDir, basePath, crName, prefix)
@@ -45,7 +67,6 @@ func ResolveOutputPath(strategy TarballNamingStrategy, basePath, crName, prefix
 		return dirPath, nil
 
 	case UseUUID:
-		fmt.Printf("Using strategy: UseUUID for %s\n", prefix)
 		dirPath := filepath.Join(basePath, fmt.Sprintf("%s-%s", prefix, uuid.NewString()))
 		if err := os.MkdirAll(dirPath, 0o755); err != nil {
 			return "", fmt.Errorf("failed to create uuid dir: %w", err)
@@ -53,7 +74,6 @@ func ResolveOutputPath(strategy TarballNamingStrategy, basePath, crName, prefix
 		return dirPath, nil
 
 	default:
-		fmt.Printf("Invalid strategy, defaulting to IsolatedTempDir for %s\n", prefix)
 		return ResolveOutputPath(IsolatedTempDir, basePath, crName, prefix)
 	}
 }
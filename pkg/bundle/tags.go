@@ -0,0 +1,121 @@
+// File: pkg/bundle/tags.go
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// ErrNoMatchingTag is returned when no tag in the repository is accepted by
+// a TagPolicy.
+var ErrNoMatchingTag = errors.New("no tag matches the update policy")
+
+// TagPolicy selects the newest acceptable tag of a repository. Tags are first
+// filtered by Pattern; with a SemVer constraint the highest matching version
+// wins, otherwise the lexically greatest tag does.
+type TagPolicy struct {
+	SemVer  *semver.Constraints
+	Pattern *regexp.Regexp
+}
+
+// NewTagPolicy parses a semver constraint (e.g. "^2.3") and a tag regular
+// expression. Either may be empty, but not both.
+func NewTagPolicy(constraint, pattern string) (*TagPolicy, error) {
+	if constraint == "" && pattern == "" {
+		return nil, errors.New("update policy needs a semver constraint or a tag pattern")
+	}
+	p := &TagPolicy{}
+	if constraint != "" {
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver constraint %q: %w", constraint, err)
+		}
+		p.SemVer = c
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+		}
+		p.Pattern = re
+	}
+	return p, nil
+}
+
+// Select returns the newest tag accepted by the policy.
+func (p *TagPolicy) Select(tags []string) (string, error) {
+	var candidates []string
+	for _, tag := range tags {
+		if p.Pattern == nil || p.Pattern.MatchString(tag) {
+			candidates = append(candidates, tag)
+		}
+	}
+
+	if p.SemVer == nil {
+		if len(candidates) == 0 {
+			return "", ErrNoMatchingTag
+		}
+		sort.Strings(candidates)
+		return candidates[len(candidates)-1], nil
+	}
+
+	var best *semver.Version
+	var bestTag string
+	for _, tag := range candidates {
+		v, err := semver.NewVersion(tag)
+		if err != nil || !p.SemVer.Check(v) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best, bestTag = v, tag
+		}
+	}
+	if best == nil {
+		return "", ErrNoMatchingTag
+	}
+	return bestTag, nil
+}
+
+// LatestTag lists the tags of the repository named by ref (any tag or digest
+// in ref is ignored) and resolves the newest one accepted by policy.
+func LatestTag(ctx context.Context, ref string, policy *TagPolicy) (string, ocispec.Descriptor, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", ocispec.Descriptor{}, fmt.Errorf("failed to create remote repository: %w", err)
+	}
+
+	var tags []string
+	if err := repo.Tags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return "", ocispec.Descriptor{}, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	tag, err := policy.Select(tags)
+	if err != nil {
+		return "", ocispec.Descriptor{}, err
+	}
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return "", ocispec.Descriptor{}, fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+	return tag, desc, nil
+}
+
+// RepositoryName returns "<registry>/<repository>" for ref, dropping any tag
+// or digest.
+func RepositoryName(ref string) (string, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+	return repo.Reference.Registry + "/" + repo.Reference.Repository, nil
+}
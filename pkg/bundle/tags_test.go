@@ -0,0 +1,51 @@
+// File: pkg/bundle/tags_test.go
+package bundle_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/bundle"
+)
+
+func TestTagPolicySelect(t *testing.T) {
+	tags := []string{"latest", "v2.2.9", "v2.3.0", "v2.3.4", "v2.4.0-rc.1", "v2.10.1", "v3.0.0", "main-20240102", "main-20240315"}
+
+	cases := []struct {
+		name       string
+		constraint string
+		pattern    string
+		want       string
+	}{
+		{name: "caret range", constraint: "^2.3", want: "v2.10.1"},
+		{name: "tilde range", constraint: "~2.3", want: "v2.3.4"},
+		{name: "pattern only", pattern: `^main-\d+$`, want: "main-20240315"},
+		{name: "pattern and range", constraint: ">=2.0.0", pattern: `^v2\.3\.`, want: "v2.3.4"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := bundle.NewTagPolicy(tc.constraint, tc.pattern)
+			require.NoError(t, err)
+			got, err := policy.Select(tags)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTagPolicyNoMatch(t *testing.T) {
+	policy, err := bundle.NewTagPolicy("^4", "")
+	require.NoError(t, err)
+	_, err = policy.Select([]string{"v1.0.0", "v3.2.1"})
+	assert.True(t, errors.Is(err, bundle.ErrNoMatchingTag))
+}
+
+func TestNewTagPolicyRequiresCriteria(t *testing.T) {
+	_, err := bundle.NewTagPolicy("", "")
+	assert.Error(t, err)
+	_, err = bundle.NewTagPolicy("not a range", "")
+	assert.Error(t, err)
+}
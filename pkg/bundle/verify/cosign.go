@@ -0,0 +1,203 @@
+// File: pkg/bundle/verify/cosign.go
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+const (
+	cosignArtifactType     = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	cosignPayloadMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignSignatureKey     = "dev.cosignproject.cosign/signature"
+	cosignCertificateKey   = "dev.sigstore.cosign/certificate"
+	cosignChainKey         = "dev.sigstore.cosign/chain"
+	cosignMaxPayloadBytes  = 1 << 20
+	fulcioIssuerV1ExtOID   = "1.3.6.1.4.1.57264.1.1"
+	fulcioIssuerV2ExtOID   = "1.3.6.1.4.1.57264.1.8"
+)
+
+// simpleSigning is the cosign signature payload.
+type simpleSigning struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// verifyCosign verifies a cosign signature manifest attached to subject. Each
+// simple-signing layer is tried in turn; the first that verifies wins.
+func verifyCosign(ctx context.Context, fetcher content.Fetcher, subject, sigDesc ocispec.Descriptor, trust *Trust) (string, error) {
+	manifest, err := fetchManifest(ctx, fetcher, sigDesc)
+	if err != nil {
+		return "", err
+	}
+
+	var lastErr error = errors.New("no simple-signing layer")
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != cosignPayloadMediaType {
+			continue
+		}
+		if layer.Size > cosignMaxPayloadBytes {
+			lastErr = fmt.Errorf("payload too large: %d bytes", layer.Size)
+			continue
+		}
+		payload, err := content.FetchAll(ctx, fetcher, layer)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch payload: %w", err)
+		}
+		signer, err := verifyCosignLayer(subject, layer, payload, trust)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return signer, nil
+	}
+	return "", lastErr
+}
+
+func verifyCosignLayer(subject, layer ocispec.Descriptor, payload []byte, trust *Trust) (string, error) {
+	var claims simpleSigning
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if claims.Critical.Image.DockerManifestDigest != subject.Digest.String() {
+		return "", fmt.Errorf("payload is for %s, not %s", claims.Critical.Image.DockerManifestDigest, subject.Digest)
+	}
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureKey])
+	if err != nil || len(sig) == 0 {
+		return "", errors.New("missing or malformed signature annotation")
+	}
+
+	if certPEM := layer.Annotations[cosignCertificateKey]; certPEM != "" {
+		cert, err := parseCertificatePEM([]byte(certPEM))
+		if err != nil {
+			return "", err
+		}
+		chain, err := ParseCertificatesPEM([]byte(layer.Annotations[cosignChainKey]))
+		if err != nil {
+			return "", err
+		}
+		if err := verifySignature(cert.PublicKey, payload, sig); err != nil {
+			return "", err
+		}
+		// The short-lived certificate is checked when the transparency log
+		// recorded the signature
+		signedAt, err := verifyLogEntry(layer.Annotations[cosignBundleKey], payload, sig, cert, trust.TransparencyLogKeys)
+		if err != nil {
+			return "", err
+		}
+		return verifyCertificate(cert, chain, trust, signedAt, certificateNames(cert))
+	}
+
+	for _, key := range trust.PublicKeys {
+		if verifySignature(key, payload, sig) == nil {
+			return publicKeyFingerprint(key), nil
+		}
+	}
+	return "", errors.New("signature does not match any trusted key")
+}
+
+// verifySignature checks a cosign signature over payload.
+func verifySignature(key crypto.PublicKey, payload, sig []byte) error {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(k, digest[:], sig) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(k, payload, sig) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return errors.New("invalid signature")
+}
+
+// ParsePublicKeyPEM parses a PEM-encoded PKIX public key.
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return key, nil
+}
+
+func publicKeyFingerprint(key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "unknown key"
+	}
+	sum := sha256.Sum256(der)
+	return fmt.Sprintf("key SHA256:%x", sum[:8])
+}
+
+func parseCertificatePEM(data []byte) (*x509.Certificate, error) {
+	certs, err := ParseCertificatesPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found")
+	}
+	return certs[0], nil
+}
+
+// ParseCertificatesPEM parses every PEM-encoded certificate in data.
+func ParseCertificatesPEM(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+}
+
+// fulcioIssuer returns the OIDC issuer recorded by Fulcio, if any.
+func fulcioIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch ext.Id.String() {
+		case fulcioIssuerV2ExtOID:
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case fulcioIssuerV1ExtOID:
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
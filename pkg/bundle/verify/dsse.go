@@ -0,0 +1,57 @@
+// File: pkg/bundle/verify/dsse.go
+package verify
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Envelope is a DSSE envelope, as used for signed in-toto attestations,
+// together with the certificates it was published with.
+type Envelope struct {
+	PayloadType string
+	Payload     []byte
+	Signatures  [][]byte
+	// Certificates holds the signing certificate followed by its chain. It
+	// is empty for envelopes signed with a raw key.
+	Certificates []*x509.Certificate
+}
+
+// VerifyEnvelope checks that one of env's signatures was made by a key or
+// certificate trusted by trust. It returns a description of the signer.
+func VerifyEnvelope(env Envelope, trust *Trust) (string, error) {
+	if len(env.Signatures) == 0 {
+		return "", errors.New("envelope is not signed")
+	}
+	pae := preAuthEncoding(env.PayloadType, env.Payload)
+
+	if len(env.Certificates) > 0 {
+		cert := env.Certificates[0]
+		var lastErr error
+		for _, sig := range env.Signatures {
+			if lastErr = verifySignature(cert.PublicKey, pae, sig); lastErr == nil {
+				// Nothing timestamps the envelope, so as for Notation
+				// signatures the certificate must be valid now
+				return verifyCertificate(cert, env.Certificates[1:], trust, time.Now(), certificateNames(cert))
+			}
+		}
+		return "", lastErr
+	}
+
+	for _, key := range trust.PublicKeys {
+		for _, sig := range env.Signatures {
+			if verifySignature(key, pae, sig) == nil {
+				return publicKeyFingerprint(key), nil
+			}
+		}
+	}
+	return "", errors.New("signature does not match any trusted key")
+}
+
+// preAuthEncoding returns the DSSE pre-authentication encoding the envelope
+// signatures are computed over.
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	return append([]byte(fmt.Sprintf("DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))), payload...)
+}
@@ -0,0 +1,116 @@
+// File: pkg/bundle/verify/rekor.go
+package verify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// cosignBundleKey is the layer annotation holding the Rekor entry of a
+// cosign signature.
+const cosignBundleKey = "dev.sigstore.cosign/bundle"
+
+// rekorBundle is the Rekor entry cosign attaches to a signature: the log's
+// signed promise that the entry was integrated at IntegratedTime.
+type rekorBundle struct {
+	SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+	Payload              struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogIndex       int64  `json:"logIndex"`
+		LogID          string `json:"logID"`
+	} `json:"Payload"`
+}
+
+// hashedRekord is the body of a Rekor hashedrekord entry.
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// verifyLogEntry checks that bundleJSON is a Rekor entry signed by one of
+// the trusted log keys that records sig by cert over payload, and returns
+// the time the log integrated it. That time, not the certificate's own
+// validity, proves the signature was made while the certificate was valid.
+func verifyLogEntry(bundleJSON string, payload, sig []byte, cert *x509.Certificate, logKeys []crypto.PublicKey) (time.Time, error) {
+	if len(logKeys) == 0 {
+		return time.Time{}, errors.New("certificate signatures require transparency log keys in the policy")
+	}
+	if bundleJSON == "" {
+		return time.Time{}, errors.New("certificate signature has no transparency log entry")
+	}
+	var bundle rekorBundle
+	if err := json.Unmarshal([]byte(bundleJSON), &bundle); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode transparency log entry: %w", err)
+	}
+
+	// The signed entry timestamp covers the canonical JSON of the payload,
+	// whose keys are sorted
+	canonical, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{bundle.Payload.Body, bundle.Payload.IntegratedTime, bundle.Payload.LogID, bundle.Payload.LogIndex})
+	if err != nil {
+		return time.Time{}, err
+	}
+	trusted := false
+	for _, key := range logKeys {
+		if verifySignature(key, canonical, bundle.SignedEntryTimestamp) == nil {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return time.Time{}, errors.New("transparency log entry is not signed by a trusted log")
+	}
+
+	body, err := base64.StdEncoding.DecodeString(bundle.Payload.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode transparency log entry body: %w", err)
+	}
+	var entry hashedRekord
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode transparency log entry body: %w", err)
+	}
+	if entry.Kind != "hashedrekord" {
+		return time.Time{}, fmt.Errorf("unsupported transparency log entry kind %q", entry.Kind)
+	}
+	sum := sha256.Sum256(payload)
+	if entry.Spec.Data.Hash.Algorithm != "sha256" || entry.Spec.Data.Hash.Value != hex.EncodeToString(sum[:]) {
+		return time.Time{}, errors.New("transparency log entry is for another payload")
+	}
+	if logged, err := base64.StdEncoding.DecodeString(entry.Spec.Signature.Content); err != nil || !bytes.Equal(logged, sig) {
+		return time.Time{}, errors.New("transparency log entry is for another signature")
+	}
+	certPEM, err := base64.StdEncoding.DecodeString(entry.Spec.Signature.PublicKey.Content)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode transparency log entry certificate: %w", err)
+	}
+	if logged, err := parseCertificatePEM(certPEM); err != nil || !logged.Equal(cert) {
+		return time.Time{}, errors.New("transparency log entry is for another certificate")
+	}
+	return time.Unix(bundle.Payload.IntegratedTime, 0), nil
+}
@@ -0,0 +1,177 @@
+// File: pkg/bundle/verify/verify.go
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+var (
+	// ErrUnsigned is returned when the artifact carries no signature of a
+	// scheme the policy accepts.
+	ErrUnsigned = errors.New("artifact is not signed")
+	// ErrUntrusted is returned when signatures exist but none of them
+	// verifies against the policy.
+	ErrUntrusted = errors.New("no trusted signature")
+)
+
+// Policy lists the signers trusted for an artifact. A signature accepted by
+// either scheme satisfies the policy; a nil scheme is not consulted.
+type Policy struct {
+	Cosign   *Trust
+	Notation *Trust
+}
+
+// Trust describes the keys and certificate identities trusted by one scheme.
+type Trust struct {
+	// PublicKeys verify signatures made with a raw key (cosign only).
+	PublicKeys []crypto.PublicKey
+	// Roots anchor certificate-based signatures.
+	Roots *x509.CertPool
+	// Identities restrict which certificates chaining to Roots are trusted.
+	// When empty, any such certificate is trusted.
+	Identities []Identity
+	// TransparencyLogKeys verify the Rekor entries that timestamp cosign
+	// certificate signatures. Without them such signatures are rejected.
+	TransparencyLogKeys []crypto.PublicKey
+}
+
+// Identity matches a signing certificate.
+type Identity struct {
+	// Subject is matched against the certificate's SAN emails and URIs and,
+	// for Notation, its subject DN.
+	Subject *regexp.Regexp
+	// Issuer, when set, must equal the Fulcio OIDC issuer extension.
+	Issuer string
+}
+
+// Result describes the signature that satisfied the policy.
+type Result struct {
+	Scheme string
+	Signer string
+}
+
+// Verify checks that subject carries at least one signature, attached as an
+// OCI referrer in store, that is accepted by policy.
+func Verify(ctx context.Context, store content.ReadOnlyGraphStorage, subject ocispec.Descriptor, policy Policy) (*Result, error) {
+	referrers, err := registry.Referrers(ctx, store, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers: %w", err)
+	}
+
+	var failures []string
+	signed := false
+	for _, ref := range referrers {
+		var verifier func(context.Context, content.Fetcher, ocispec.Descriptor, ocispec.Descriptor, *Trust) (string, error)
+		var trust *Trust
+		var scheme string
+		switch ref.ArtifactType {
+		case cosignArtifactType:
+			verifier, trust, scheme = verifyCosign, policy.Cosign, "cosign"
+		case notationArtifactType:
+			verifier, trust, scheme = verifyNotation, policy.Notation, "notation"
+		default:
+			continue
+		}
+		if trust == nil {
+			continue
+		}
+		signed = true
+
+		signer, err := verifier(ctx, store, subject, ref, trust)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s signature %s: %v", scheme, ref.Digest, err))
+			continue
+		}
+		return &Result{Scheme: scheme, Signer: signer}, nil
+	}
+
+	if !signed {
+		return nil, ErrUnsigned
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUntrusted, strings.Join(failures, "; "))
+}
+
+// fetchManifest fetches and decodes the image manifest described by desc.
+func fetchManifest(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	data, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signature manifest: %w", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode signature manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// verifyCertificate checks that cert chains to the trusted roots at time at
+// and that one of names matches a trusted identity. It returns a description
+// of the matched signer.
+func verifyCertificate(cert *x509.Certificate, intermediates []*x509.Certificate, trust *Trust, at time.Time, names []string) (string, error) {
+	if trust.Roots == nil {
+		return "", errors.New("certificate signatures are not trusted by this policy")
+	}
+	pool := x509.NewCertPool()
+	for _, c := range intermediates {
+		pool.AddCert(c)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         trust.Roots,
+		Intermediates: pool,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return "", fmt.Errorf("certificate is not trusted: %w", err)
+	}
+
+	if len(trust.Identities) == 0 {
+		if len(names) == 0 {
+			return cert.Subject.String(), nil
+		}
+		return names[0], nil
+	}
+	issuer := fulcioIssuer(cert)
+	for _, id := range trust.Identities {
+		if id.Issuer != "" && id.Issuer != issuer {
+			continue
+		}
+		for _, name := range names {
+			if id.Subject.MatchString(name) {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("certificate identity %v (issuer %q) is not trusted", names, issuer)
+}
+
+// certificateNames returns the identities a sigstore certificate can be
+// matched by: its SAN emails and URIs. The subject DN is not used, as Fulcio
+// leaves it empty and any other CA the policy trusts may put arbitrary text
+// in it.
+func certificateNames(cert *x509.Certificate) []string {
+	names := append([]string(nil), cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+	return names
+}
+
+// notationNames returns the identities a Notation certificate can be matched
+// by. Notary Project trust policies identify signers by subject DN, so it is
+// included, formatted per RFC 2253 (e.g. "CN=release,O=MyCorp"), after the
+// SAN emails and URIs.
+func notationNames(cert *x509.Certificate) []string {
+	return append(certificateNames(cert), cert.Subject.String())
+}
@@ -0,0 +1,449 @@
+// File: pkg/bundle/verify/verify_test.go
+package verify_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+
+	"mfe-operator/pkg/bundle/verify"
+)
+
+func push(t *testing.T, store *memory.Store, mediaType string, data []byte) ocispec.Descriptor {
+	desc := content.NewDescriptorFromBytes(mediaType, data)
+	if err := store.Push(context.Background(), desc, bytes.NewReader(data)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		require.NoError(t, err)
+	}
+	return desc
+}
+
+// pushSubject stores a minimal bundle manifest and returns its descriptor.
+func pushSubject(t *testing.T, store *memory.Store) ocispec.Descriptor {
+	layer := push(t, store, ocispec.MediaTypeImageLayerGzip, []byte("bundle"))
+	config := push(t, store, ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	return pushManifest(t, store, ocispec.Manifest{Config: config, Layers: []ocispec.Descriptor{layer}})
+}
+
+func pushManifest(t *testing.T, store *memory.Store, manifest ocispec.Manifest) ocispec.Descriptor {
+	manifest.SchemaVersion = 2
+	manifest.MediaType = ocispec.MediaTypeImageManifest
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	return push(t, store, ocispec.MediaTypeImageManifest, data)
+}
+
+// attachCosign attaches a cosign signature made by key, optionally carrying
+// a signing certificate.
+func attachCosign(t *testing.T, store *memory.Store, subject ocispec.Descriptor, key *ecdsa.PrivateKey, certPEM []byte) {
+	attachCosignLogged(t, store, subject, key, certPEM, nil, time.Time{})
+}
+
+// attachCosignLogged is attachCosign with a Rekor entry for the signature
+// signed by logKey and integrated at loggedAt, when logKey is set.
+func attachCosignLogged(t *testing.T, store *memory.Store, subject ocispec.Descriptor, key *ecdsa.PrivateKey, certPEM []byte,
+	logKey *ecdsa.PrivateKey, loggedAt time.Time) {
+	payload := []byte(`{"critical":{"identity":{"docker-reference":"registry.example/mfe"},"image":{"docker-manifest-digest":"` +
+		subject.Digest.String() + `"},"type":"cosign container image signature"},"optional":null}`)
+	digest := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	layer := push(t, store, "application/vnd.dev.cosign.simplesigning.v1+json", payload)
+	layer.Annotations = map[string]string{"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(sig)}
+	if certPEM != nil {
+		layer.Annotations["dev.sigstore.cosign/certificate"] = string(certPEM)
+	}
+	if logKey != nil {
+		layer.Annotations["dev.sigstore.cosign/bundle"] = rekorBundle(t, logKey, loggedAt, digest[:], sig, certPEM)
+	}
+	config := push(t, store, ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	pushManifest(t, store, ocispec.Manifest{
+		ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json",
+		Config:       config,
+		Layers:       []ocispec.Descriptor{layer},
+		Subject:      &subject,
+	})
+}
+
+// rekorBundle returns the cosign bundle annotation for a hashedrekord entry
+// of sig by certPEM over a payload with the given digest.
+func rekorBundle(t *testing.T, logKey *ecdsa.PrivateKey, loggedAt time.Time, digest, sig, certPEM []byte) string {
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]any{
+			"data": map[string]any{"hash": map[string]any{"algorithm": "sha256", "value": fmt.Sprintf("%x", digest)}},
+			"signature": map[string]any{
+				"content":   base64.StdEncoding.EncodeToString(sig),
+				"publicKey": map[string]any{"content": base64.StdEncoding.EncodeToString(certPEM)},
+			},
+		},
+	})
+	require.NoError(t, err)
+	payload := map[string]any{
+		"body":           base64.StdEncoding.EncodeToString(body),
+		"integratedTime": loggedAt.Unix(),
+		"logID":          "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d",
+		"logIndex":       42,
+	}
+	canonical, err := json.Marshal(payload)
+	require.NoError(t, err)
+	set := sha256.Sum256(canonical)
+	setSig, err := ecdsa.SignASN1(rand.Reader, logKey, set[:])
+	require.NoError(t, err)
+	bundle, err := json.Marshal(map[string]any{"SignedEntryTimestamp": setSig, "Payload": payload})
+	require.NoError(t, err)
+	return string(bundle)
+}
+
+// attachNotation attaches a Notary Project JWS signature made by key at
+// signingTime with the given certificate chain.
+func attachNotation(t *testing.T, store *memory.Store, subject ocispec.Descriptor, signingTime time.Time, key *ecdsa.PrivateKey, chain ...*x509.Certificate) {
+	header, err := json.Marshal(map[string]any{
+		"alg":                          "ES256",
+		"crit":                         []string{"io.cncf.notary.signingScheme"},
+		"cty":                          "application/vnd.cncf.notary.payload.v1+json",
+		"io.cncf.notary.signingScheme": "notary.x509",
+		"io.cncf.notary.signingTime":   signingTime.UTC().Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	payload, err := json.Marshal(map[string]any{"targetArtifact": subject})
+	require.NoError(t, err)
+
+	protected := base64.RawURLEncoding.EncodeToString(header)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(protected + "." + encodedPayload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	var x5c []string
+	for _, c := range chain {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(c.Raw))
+	}
+	envelope, err := json.Marshal(map[string]any{
+		"payload":   encodedPayload,
+		"protected": protected,
+		"header":    map[string]any{"x5c": x5c},
+		"signature": base64.RawURLEncoding.EncodeToString(sig),
+	})
+	require.NoError(t, err)
+
+	layer := push(t, store, "application/jose+json", envelope)
+	config := push(t, store, ocispec.MediaTypeEmptyJSON, []byte("{}"))
+	pushManifest(t, store, ocispec.Manifest{
+		ArtifactType: "application/vnd.cncf.notary.signature",
+		Config:       config,
+		Layers:       []ocispec.Descriptor{layer},
+		Subject:      &subject,
+	})
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+// newCA returns a self-signed CA and a code-signing leaf issued by it.
+func newCA(t *testing.T, email string) (ca, leaf *x509.Certificate, leafKey *ecdsa.PrivateKey) {
+	return newCAWithLeafValidity(t, email, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+}
+
+// newCAWithLeafValidity is newCA with the leaf valid from notBefore to
+// notAfter.
+func newCAWithLeafValidity(t *testing.T, email string, notBefore, notAfter time.Time) (ca, leaf *x509.Certificate, leafKey *ecdsa.PrivateKey) {
+	caKey := newKey(t)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             notBefore.Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+	ca, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	leafKey = newKey(t)
+	leafTemplate := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		Subject:        pkix.Name{CommonName: "mfe-ci", Organization: []string{"MyCorp"}},
+		EmailAddresses: []string{email},
+		NotBefore:      notBefore,
+		NotAfter:       notAfter,
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	der, err = x509.CreateCertificate(rand.Reader, leafTemplate, ca, leafKey.Public(), caKey)
+	require.NoError(t, err)
+	leaf, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return ca, leaf, leafKey
+}
+
+func rootPool(certs ...*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, c := range certs {
+		pool.AddCert(c)
+	}
+	return pool
+}
+
+func TestVerifyCosignKey(t *testing.T) {
+	store := memory.New()
+	subject := pushSubject(t, store)
+	key := newKey(t)
+	attachCosign(t, store, subject, key, nil)
+
+	res, err := verify.Verify(context.Background(), store, subject, verify.Policy{
+		Cosign: &verify.Trust{PublicKeys: []crypto.PublicKey{key.Public()}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cosign", res.Scheme)
+}
+
+func TestVerifyCosignWrongKey(t *testing.T) {
+	store := memory.New()
+	subject := pushSubject(t, store)
+	attachCosign(t, store, subject, newKey(t), nil)
+
+	_, err := verify.Verify(context.Background(), store, subject, verify.Policy{
+		Cosign: &verify.Trust{PublicKeys: []crypto.PublicKey{newKey(t).Public()}},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	store := memory.New()
+	subject := pushSubject(t, store)
+
+	_, err := verify.Verify(context.Background(), store, subject, verify.Policy{
+		Cosign:   &verify.Trust{PublicKeys: []crypto.PublicKey{newKey(t).Public()}},
+		Notation: &verify.Trust{Roots: x509.NewCertPool()},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUnsigned), "got %v", err)
+}
+
+func TestVerifyCosignCertificateIdentity(t *testing.T) {
+	store := memory.New()
+	subject := pushSubject(t, store)
+	ca, leaf, leafKey := newCA(t, "ci@mycorp.com")
+	logKey := newKey(t)
+	attachCosignLogged(t, store, subject, leafKey, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw}), logKey, time.Now())
+
+	res, err := verify.Verify(context.Background(), store, subject, verify.Policy{
+		Cosign: &verify.Trust{
+			Roots:               rootPool(ca),
+			Identities:          []verify.Identity{{Subject: regexp.MustCompile(`^ci@mycorp\.com$`)}},
+			TransparencyLogKeys: []crypto.PublicKey{logKey.Public()},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ci@mycorp.com", res.Signer)
+
+	_, err = verify.Verify(context.Background(), store, subject, verify.Policy{
+		Cosign: &verify.Trust{
+			Roots:               rootPool(ca),
+			Identities:          []verify.Identity{{Subject: regexp.MustCompile(`^release@mycorp\.com$`)}},
+			TransparencyLogKeys: []crypto.PublicKey{logKey.Public()},
+		},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+
+	// The subject DN does not identify cosign signers
+	_, err = verify.Verify(context.Background(), store, subject, verify.Policy{
+		Cosign: &verify.Trust{
+			Roots:               rootPool(ca),
+			Identities:          []verify.Identity{{Subject: regexp.MustCompile(`^CN=mfe-ci,O=MyCorp$`)}},
+			TransparencyLogKeys: []crypto.PublicKey{logKey.Public()},
+		},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+}
+
+func TestVerifyCosignCertificateNeedsLogEntry(t *testing.T) {
+	expired := time.Now().Add(-24 * time.Hour)
+	ca, leaf, leafKey := newCAWithLeafValidity(t, "ci@mycorp.com", expired.Add(-10*time.Minute), expired)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	logKey := newKey(t)
+	policy := verify.Policy{Cosign: &verify.Trust{Roots: rootPool(ca), TransparencyLogKeys: []crypto.PublicKey{logKey.Public()}}}
+
+	tests := []struct {
+		name     string
+		logKey   *ecdsa.PrivateKey
+		loggedAt time.Time
+		policy   verify.Policy
+		trusted  bool
+	}{
+		{name: "logged while valid", logKey: logKey, loggedAt: expired.Add(-time.Minute), policy: policy, trusted: true},
+		{name: "logged after expiry", logKey: logKey, loggedAt: time.Now(), policy: policy},
+		{name: "not logged", policy: policy},
+		{name: "logged by an untrusted log", logKey: newKey(t), loggedAt: expired.Add(-time.Minute), policy: policy},
+		{name: "no trusted log", logKey: logKey, loggedAt: expired.Add(-time.Minute),
+			policy: verify.Policy{Cosign: &verify.Trust{Roots: rootPool(ca)}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := memory.New()
+			subject := pushSubject(t, store)
+			attachCosignLogged(t, store, subject, leafKey, certPEM, tc.logKey, tc.loggedAt)
+			_, err := verify.Verify(context.Background(), store, subject, tc.policy)
+			if tc.trusted {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+			}
+		})
+	}
+
+	// An entry logging another signature does not timestamp this one
+	store := memory.New()
+	subject := pushSubject(t, store)
+	payload := []byte(`{"critical":{"image":{"docker-manifest-digest":"` + subject.Digest.String() + `"}}}`)
+	digest := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, leafKey, digest[:])
+	require.NoError(t, err)
+	otherSig, err := ecdsa.SignASN1(rand.Reader, leafKey, digest[:])
+	require.NoError(t, err)
+	layer := push(t, store, "application/vnd.dev.cosign.simplesigning.v1+json", payload)
+	layer.Annotations = map[string]string{
+		"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(sig),
+		"dev.sigstore.cosign/certificate":    string(certPEM),
+		"dev.sigstore.cosign/bundle":         rekorBundle(t, logKey, expired.Add(-time.Minute), digest[:], otherSig, certPEM),
+	}
+	pushManifest(t, store, ocispec.Manifest{
+		ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json",
+		Config:       push(t, store, ocispec.MediaTypeEmptyJSON, []byte("{}")),
+		Layers:       []ocispec.Descriptor{layer},
+		Subject:      &subject,
+	})
+	_, err = verify.Verify(context.Background(), store, subject, policy)
+	require.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+	assert.Contains(t, err.Error(), "another signature")
+}
+
+func TestVerifyNotation(t *testing.T) {
+	store := memory.New()
+	subject := pushSubject(t, store)
+	ca, leaf, leafKey := newCA(t, "ci@mycorp.com")
+	attachNotation(t, store, subject, time.Now(), leafKey, leaf, ca)
+
+	res, err := verify.Verify(context.Background(), store, subject, verify.Policy{
+		Notation: &verify.Trust{
+			Roots:      rootPool(ca),
+			Identities: []verify.Identity{{Subject: regexp.MustCompile(`^ci@mycorp\.com$`)}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "notation", res.Scheme)
+
+	// Notation signers are also identified by subject DN
+	res, err = verify.Verify(context.Background(), store, subject, verify.Policy{
+		Notation: &verify.Trust{
+			Roots:      rootPool(ca),
+			Identities: []verify.Identity{{Subject: regexp.MustCompile(`^CN=mfe-ci,O=MyCorp$`)}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CN=mfe-ci,O=MyCorp", res.Signer)
+	_, err = verify.Verify(context.Background(), store, subject, verify.Policy{
+		Notation: &verify.Trust{
+			Roots:      rootPool(ca),
+			Identities: []verify.Identity{{Subject: regexp.MustCompile(`^CN=release,O=MyCorp$`)}},
+		},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+
+	otherCA, _, _ := newCA(t, "ci@mycorp.com")
+	_, err = verify.Verify(context.Background(), store, subject, verify.Policy{
+		Notation: &verify.Trust{Roots: rootPool(otherCA)},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+}
+
+func TestVerifyNotationIgnoresClaimedSigningTime(t *testing.T) {
+	store := memory.New()
+	subject := pushSubject(t, store)
+	expired := time.Now().Add(-24 * time.Hour)
+	ca, leaf, leafKey := newCAWithLeafValidity(t, "ci@mycorp.com", expired.Add(-time.Hour), expired)
+	// Backdated into the expired certificate's validity
+	attachNotation(t, store, subject, expired.Add(-time.Minute), leafKey, leaf, ca)
+
+	_, err := verify.Verify(context.Background(), store, subject, verify.Policy{
+		Notation: &verify.Trust{Roots: rootPool(ca)},
+	})
+	assert.True(t, errors.Is(err, verify.ErrUntrusted), "got %v", err)
+}
+
+// signEnvelope signs a DSSE envelope over payload with key.
+func signEnvelope(t *testing.T, key *ecdsa.PrivateKey, payload []byte) verify.Envelope {
+	payloadType := "application/vnd.in-toto+json"
+	pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+	digest := sha256.Sum256([]byte(pae))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	return verify.Envelope{PayloadType: payloadType, Payload: payload, Signatures: [][]byte{sig}}
+}
+
+func TestVerifyEnvelope(t *testing.T) {
+	key := newKey(t)
+	env := signEnvelope(t, key, []byte(`{"predicateType":"https://slsa.dev/provenance/v1"}`))
+
+	_, err := verify.VerifyEnvelope(env, &verify.Trust{PublicKeys: []crypto.PublicKey{key.Public()}})
+	assert.NoError(t, err)
+
+	_, err = verify.VerifyEnvelope(env, &verify.Trust{PublicKeys: []crypto.PublicKey{newKey(t).Public()}})
+	assert.Error(t, err)
+
+	tampered := env
+	tampered.Payload = []byte(`{"predicateType":"https://slsa.dev/provenance/v0.2"}`)
+	_, err = verify.VerifyEnvelope(tampered, &verify.Trust{PublicKeys: []crypto.PublicKey{key.Public()}})
+	assert.Error(t, err)
+
+	unsigned := env
+	unsigned.Signatures = nil
+	_, err = verify.VerifyEnvelope(unsigned, &verify.Trust{PublicKeys: []crypto.PublicKey{key.Public()}})
+	assert.Error(t, err)
+}
+
+func TestVerifyEnvelopeCertificate(t *testing.T) {
+	ca, leaf, leafKey := newCA(t, "ci@mycorp.example")
+	env := signEnvelope(t, leafKey, []byte(`{}`))
+	env.Certificates = []*x509.Certificate{leaf}
+
+	signer, err := verify.VerifyEnvelope(env, &verify.Trust{Roots: rootPool(ca)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, signer)
+
+	otherCA, _, _ := newCA(t, "ci@mycorp.example")
+	_, err = verify.VerifyEnvelope(env, &verify.Trust{Roots: rootPool(otherCA)})
+	assert.Error(t, err)
+}
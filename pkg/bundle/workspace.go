@@ -0,0 +1,208 @@
+// File: pkg/bundle/workspace.go
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrWorkspaceFull is returned when the workspace root exceeds its disk limit.
+var ErrWorkspaceFull = errors.New("workspace disk limit exceeded")
+
+// WorkspaceManager owns the fetch and extract directories used by each
+// reconcile. Every workspace lives under <basePath>/workspaces and is removed
+// when the reconcile finishes, whatever the outcome.
+type WorkspaceManager struct {
+	root     string
+	strategy TarballNamingStrategy
+	maxBytes int64
+	// written counts the bytes fetched and extracted by open workspaces.
+	written int64
+}
+
+// Workspace is a directory owned by a single reconcile.
+type Workspace struct {
+	Dir string
+
+	manager *WorkspaceManager
+	quota   *quota
+}
+
+// quota charges the bytes a workspace writes against its manager's disk
+// limit, so the limit also holds in the middle of a fetch or an extraction.
+// A nil quota is unlimited.
+type quota struct {
+	manager *WorkspaceManager
+	charged int64
+}
+
+// newQuota returns a quota against m's limit, or nil when there is none.
+func (m *WorkspaceManager) newQuota() *quota {
+	if m == nil || m.maxBytes <= 0 {
+		return nil
+	}
+	return &quota{manager: m}
+}
+
+// writer returns dst, failing writes with ErrWorkspaceFull once the limit
+// is exceeded.
+func (q *quota) writer(dst io.Writer) io.Writer {
+	if q == nil {
+		return dst
+	}
+	return &quotaWriter{dst: dst, quota: q}
+}
+
+// release returns the bytes charged so far to the manager.
+func (q *quota) release() {
+	if q != nil {
+		atomic.AddInt64(&q.manager.written, -atomic.SwapInt64(&q.charged, 0))
+	}
+}
+
+type quotaWriter struct {
+	dst   io.Writer
+	quota *quota
+}
+
+func (w *quotaWriter) Write(p []byte) (int, error) {
+	m, n := w.quota.manager, int64(len(p))
+	atomic.AddInt64(&w.quota.charged, n)
+	if written := atomic.AddInt64(&m.written, n); written > m.maxBytes {
+		return 0, fmt.Errorf("%w: %d of %d bytes written", ErrWorkspaceFull, written, m.maxBytes)
+	}
+	return w.dst.Write(p)
+}
+
+// NewWorkspaceManager creates a manager rooted at basePath. Any workspace left
+// behind by a previous run is garbage-collected. A maxBytes of zero or less
+// disables the disk limit.
+func NewWorkspaceManager(basePath string, strategy TarballNamingStrategy, maxBytes int64) (*WorkspaceManager, error) {
+	m := &WorkspaceManager{
+		root:     filepath.Join(basePath, "workspaces"),
+		strategy: strategy,
+		maxBytes: maxBytes,
+	}
+	if err := os.MkdirAll(m.root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create workspace root: %w", err)
+	}
+	if err := m.collectOrphans(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Strategy returns the operator-wide default naming strategy.
+func (m *WorkspaceManager) Strategy() TarballNamingStrategy {
+	return m.strategy
+}
+
+// Acquire creates a workspace for the CR using the default strategy.
+func (m *WorkspaceManager) Acquire(namespace, name string) (*Workspace, error) {
+	return m.AcquireWithStrategy(namespace, name, m.strategy)
+}
+
+// AcquireWithStrategy creates a workspace for the CR using the given strategy.
+// With UseCRName the directory is keyed by namespace and name, and anything
+// left over from a previous run of the same CR is wiped first so that stale
+// files never leak into a new version.
+func (m *WorkspaceManager) AcquireWithStrategy(namespace, name string, strategy TarballNamingStrategy) (*Workspace, error) {
+	if err := m.checkUsage(); err != nil {
+		return nil, err
+	}
+	// Namespaces cannot contain '_', so this key is unambiguous.
+	crName := namespace + "_" + name
+	if strategy == UseCRName {
+		if sanitized := SanitizeName(crName); sanitized != "" {
+			if err := os.RemoveAll(filepath.Join(m.root, sanitized)); err != nil {
+				return nil, fmt.Errorf("failed to wipe workspace: %w", err)
+			}
+		}
+	}
+	dir, err := ResolveOutputPath(strategy, m.root, crName, "ws")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return &Workspace{Dir: dir, manager: m, quota: m.newQuota()}, nil
+}
+
+// Fetch downloads the bundle layer of art into the workspace and returns the
+// tarball path.
+func (w *Workspace) Fetch(ctx context.Context, art *Artifact) (string, error) {
+	if err := w.manager.checkUsage(); err != nil {
+		return "", err
+	}
+	outDir, err := ResolveOutputPath(UseCRName, w.Dir, "fetch", "fetch")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	filePath := filepath.Join(outDir, "bundle.tar.gz")
+	if err := fetchBlobToFile(ctx, art.Repository, art.Layer, filePath, w.quota); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// Extract extracts tarballPath into the workspace and returns the directory.
+func (w *Workspace) Extract(ctx context.Context, tarballPath string) (string, error) {
+	if err := w.manager.checkUsage(); err != nil {
+		return "", err
+	}
+	destDir, err := ResolveOutputPath(UseCRName, w.Dir, "extract", "extract")
+	if err != nil {
+		return "", err
+	}
+	if err := extractTarballInto(ctx, tarballPath, destDir, w.quota); err != nil {
+		return "", err
+	}
+	return destDir, nil
+}
+
+// Close removes the workspace and everything in it.
+func (w *Workspace) Close() error {
+	if err := os.RemoveAll(w.Dir); err != nil {
+		return fmt.Errorf("failed to remove workspace: %w", err)
+	}
+	w.quota.release()
+	return nil
+}
+
+// checkUsage fails with ErrWorkspaceFull once the workspaces on disk exceed
+// the configured limit.
+func (m *WorkspaceManager) checkUsage() error {
+	if m.maxBytes <= 0 {
+		return nil
+	}
+	used, err := dirSize(m.root)
+	if err != nil {
+		return err
+	}
+	if used > m.maxBytes {
+		return fmt.Errorf("%w: %d of %d bytes in use", ErrWorkspaceFull, used, m.maxBytes)
+	}
+	return nil
+}
+
+// collectOrphans removes every workspace under the root. It is only safe to
+// call before any reconcile has started.
+func (m *WorkspaceManager) collectOrphans() error {
+	entries, err := os.ReadDir(m.root)
+	if err != nil {
+		return fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	for _, e := range entries {
+		path := filepath.Join(m.root, e.Name())
+		log.Log.WithName("workspaces").Info("Removing orphaned workspace", "path", path)
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove orphaned workspace: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// File: pkg/bundle/workspace_test.go
+package bundle_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/bundle"
+)
+
+func TestWorkspaceCRNameIsWipedBeforeReuse(t *testing.T) {
+	m, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.UseCRName, 0)
+	require.NoError(t, err)
+
+	ws, err := m.Acquire("default", "shop-cart")
+	require.NoError(t, err)
+	stale := filepath.Join(ws.Dir, "stale.js")
+	require.NoError(t, os.WriteFile(stale, []byte("old"), 0o644))
+
+	again, err := m.Acquire("default", "shop-cart")
+	require.NoError(t, err)
+	assert.Equal(t, ws.Dir, again.Dir)
+	assert.NoFileExists(t, stale)
+}
+
+func TestWorkspaceCRNameIsNamespaced(t *testing.T) {
+	m, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.UseCRName, 0)
+	require.NoError(t, err)
+
+	a, err := m.Acquire("team-a", "cart")
+	require.NoError(t, err)
+	b, err := m.Acquire("team-b", "cart")
+	require.NoError(t, err)
+	assert.NotEqual(t, a.Dir, b.Dir)
+}
+
+func TestWorkspaceStrategyOverride(t *testing.T) {
+	m, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.UseUUID, 0)
+	require.NoError(t, err)
+
+	first, err := m.AcquireWithStrategy("default", "cart", bundle.UseCRName)
+	require.NoError(t, err)
+	second, err := m.AcquireWithStrategy("default", "cart", bundle.UseCRName)
+	require.NoError(t, err)
+	assert.Equal(t, first.Dir, second.Dir)
+}
+
+func TestParseTarballNamingStrategy(t *testing.T) {
+	for _, s := range []bundle.TarballNamingStrategy{bundle.IsolatedTempDir, bundle.UseCRName, bundle.UseUUID} {
+		parsed, err := bundle.ParseTarballNamingStrategy(s.String())
+		require.NoError(t, err)
+		assert.Equal(t, s, parsed)
+	}
+	_, err := bundle.ParseTarballNamingStrategy("bogus")
+	assert.Error(t, err)
+}
+
+func TestWorkspaceCloseRemovesDir(t *testing.T) {
+	for _, strategy := range []bundle.TarballNamingStrategy{bundle.IsolatedTempDir, bundle.UseCRName, bundle.UseUUID} {
+		m, err := bundle.NewWorkspaceManager(t.TempDir(), strategy, 0)
+		require.NoError(t, err)
+		ws, err := m.Acquire("default", "shop-cart")
+		require.NoError(t, err)
+		require.DirExists(t, ws.Dir)
+		require.NoError(t, ws.Close())
+		assert.NoDirExists(t, ws.Dir)
+	}
+}
+
+func TestWorkspaceOrphansCollectedAtStartup(t *testing.T) {
+	base := t.TempDir()
+	m, err := bundle.NewWorkspaceManager(base, bundle.UseUUID, 0)
+	require.NoError(t, err)
+	ws, err := m.Acquire("default", "shop-cart")
+	require.NoError(t, err)
+
+	_, err = bundle.NewWorkspaceManager(base, bundle.UseUUID, 0)
+	require.NoError(t, err)
+	assert.NoDirExists(t, ws.Dir)
+}
+
+func TestWorkspaceDiskLimit(t *testing.T) {
+	m, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.UseUUID, 4)
+	require.NoError(t, err)
+	ws, err := m.Acquire("default", "a")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir, "big"), []byte("12345"), 0o644))
+
+	_, err = m.Acquire("default", "b")
+	assert.True(t, errors.Is(err, bundle.ErrWorkspaceFull))
+}
+
+func TestWorkspaceDiskLimitWhileExtracting(t *testing.T) {
+	m, err := bundle.NewWorkspaceManager(t.TempDir(), bundle.UseUUID, 1024)
+	require.NoError(t, err)
+	tarball := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(tarball, makeTarball(t, map[string]string{"big.js": strings.Repeat("x", 2048)}), 0o644))
+
+	// The workspace is empty when extraction starts; the limit stops it midway
+	ws, err := m.Acquire("default", "a")
+	require.NoError(t, err)
+	_, err = ws.Extract(context.Background(), tarball)
+	assert.True(t, errors.Is(err, bundle.ErrWorkspaceFull))
+	require.NoError(t, ws.Close())
+
+	// Closing the workspace returns its bytes to the budget
+	small := filepath.Join(t.TempDir(), "small.tar.gz")
+	require.NoError(t, os.WriteFile(small, makeTarball(t, map[string]string{"small.js": "var small;"}), 0o644))
+	ws, err = m.Acquire("default", "b")
+	require.NoError(t, err)
+	_, err = ws.Extract(context.Background(), small)
+	require.NoError(t, err)
+	require.NoError(t, ws.Close())
+}
@@ -0,0 +1,73 @@
+// File: pkg/module/entrypoint.go
+package module
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultEntryPoint is the conventional Module Federation container entry.
+const DefaultEntryPoint = "remoteEntry.js"
+
+var (
+	// ErrEntryPointNotFound is returned when no file matches the entry point.
+	ErrEntryPointNotFound = errors.New("entry point not found in bundle")
+	// ErrAmbiguousEntryPoint is returned when several files match it.
+	ErrAmbiguousEntryPoint = errors.New("entry point matches more than one file")
+)
+
+// hashPlaceholder matches webpack filename placeholders, e.g. "[contenthash:8]".
+var hashPlaceholder = regexp.MustCompile(`\[(hash|contenthash|chunkhash|fullhash)(:\d+)?\]`)
+
+// ResolveEntryPoint returns the path, relative to bundlePath, of the file
+// entryPoint designates. entryPoint may be an exact path, a glob
+// ("remoteEntry.*.js") or contain webpack hash placeholders
+// ("remoteEntry.[contenthash].js"). An exact name that is missing also
+// matches a hashed variant, so "remoteEntry.js" finds "remoteEntry.3f2a9c.js".
+func ResolveEntryPoint(bundlePath, entryPoint string) (string, error) {
+	if entryPoint == "" {
+		entryPoint = DefaultEntryPoint
+	}
+	entryPoint = path.Clean(filepath.ToSlash(entryPoint))
+	if path.IsAbs(entryPoint) || entryPoint == ".." || strings.HasPrefix(entryPoint, "../") {
+		return "", fmt.Errorf("entry point %q must be relative to the bundle root", entryPoint)
+	}
+
+	pattern := hashPlaceholder.ReplaceAllString(entryPoint, "*")
+	if pattern == entryPoint && !strings.ContainsAny(entryPoint, "*?[") {
+		if info, err := os.Stat(filepath.Join(bundlePath, entryPoint)); err == nil && !info.IsDir() {
+			return entryPoint, nil
+		}
+		// Fall back to a hashed variant: dir/name.<hash>.ext
+		ext := path.Ext(entryPoint)
+		pattern = strings.TrimSuffix(entryPoint, ext) + ".*" + ext
+	}
+
+	matches, err := filepath.Glob(filepath.Join(bundlePath, filepath.FromSlash(pattern)))
+	if err != nil {
+		return "", fmt.Errorf("invalid entry point %q: %w", entryPoint, err)
+	}
+	var files []string
+	for _, m := range matches {
+		if info, err := os.Stat(m); err == nil && !info.IsDir() {
+			rel, err := filepath.Rel(bundlePath, m)
+			if err != nil {
+				return "", err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+	}
+	switch len(files) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrEntryPointNotFound, entryPoint)
+	case 1:
+		return files[0], nil
+	default:
+		return "", fmt.Errorf("%w: %s matches %s", ErrAmbiguousEntryPoint, entryPoint, strings.Join(files, ", "))
+	}
+}
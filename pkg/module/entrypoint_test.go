@@ -0,0 +1,52 @@
+// File: pkg/module/entrypoint_test.go
+package module_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/module"
+)
+
+func TestResolveEntryPoint(t *testing.T) {
+	dir := writeBundle(t, map[string]string{
+		"remoteEntry.js":               "",
+		"static/remoteEntry.9c1e4b.js": "",
+		"static/main.js":               "",
+	})
+
+	cases := []struct {
+		entryPoint string
+		want       string
+	}{
+		{entryPoint: "", want: "remoteEntry.js"},
+		{entryPoint: "./remoteEntry.js", want: "remoteEntry.js"},
+		{entryPoint: "static/remoteEntry.js", want: "static/remoteEntry.9c1e4b.js"},
+		{entryPoint: "static/remoteEntry.[contenthash:6].js", want: "static/remoteEntry.9c1e4b.js"},
+		{entryPoint: "static/remote*.js", want: "static/remoteEntry.9c1e4b.js"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.entryPoint, func(t *testing.T) {
+			got, err := module.ResolveEntryPoint(dir, tc.entryPoint)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestResolveEntryPointErrors(t *testing.T) {
+	dir := writeBundle(t, map[string]string{
+		"static/remoteEntry.1.js": "",
+		"static/remoteEntry.2.js": "",
+	})
+
+	_, err := module.ResolveEntryPoint(dir, "remoteEntry.js")
+	assert.True(t, errors.Is(err, module.ErrEntryPointNotFound))
+	_, err = module.ResolveEntryPoint(dir, "static/remoteEntry.js")
+	assert.True(t, errors.Is(err, module.ErrAmbiguousEntryPoint))
+	_, err = module.ResolveEntryPoint(dir, "../remoteEntry.js")
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+// File: pkg/module/exposes.go
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+// DiscoverExposes returns the sorted module paths (e.g. "./Cart") an
+// extracted bundle exposes, read from its federation manifest or, failing
+// that, from the module map in the entry script.
+func DiscoverExposes(bundlePath, entry string) ([]string, error) {
+	manifest, err := readFederationManifest(bundlePath, entry)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		return sortedUnique(manifest.exposes()), nil
+	}
+	exposes, err := parseExposes(filepath.Join(bundlePath, filepath.FromSlash(entry)))
+	if err != nil {
+		return nil, err
+	}
+	return sortedUnique(exposes), nil
+}
+
+// CompareExposes returns the declared modules the bundle does not expose and
+// the exposed modules that were not declared.
+func CompareExposes(declared, exposed []string) (missing, extra []string) {
+	have := map[string]bool{}
+	for _, e := range exposed {
+		have[e] = true
+	}
+	want := map[string]bool{}
+	for _, d := range declared {
+		want[d] = true
+		if !have[d] {
+			missing = append(missing, d)
+		}
+	}
+	for _, e := range exposed {
+		if !want[e] {
+			extra = append(extra, e)
+		}
+	}
+	return missing, extra
+}
+
+// parseExposes finds the container's module map: an object whose keys all
+// start with "./" and whose values are functions, e.g.
+// {"./Cart": () => import("./src/Cart")}.
+func parseExposes(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := js.Parse(parse.NewInputBytes(data), js.Options{})
+	if err != nil {
+		return nil, err
+	}
+	v := &exposesVisitor{}
+	js.Walk(v, ast)
+	return v.exposes, nil
+}
+
+type exposesVisitor struct {
+	exposes []string
+}
+
+func (v *exposesVisitor) Enter(n js.INode) js.IVisitor {
+	if obj, ok := n.(*js.ObjectExpr); ok {
+		v.exposes = append(v.exposes, moduleMap(obj)...)
+	}
+	return v
+}
+
+func (v *exposesVisitor) Exit(js.INode) {}
+
+func moduleMap(obj *js.ObjectExpr) []string {
+	if len(obj.List) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(obj.List))
+	for _, prop := range obj.List {
+		key, ok := propertyKey(prop)
+		if !ok || !strings.HasPrefix(key, "./") {
+			return nil
+		}
+		switch unparen(prop.Value).(type) {
+		case *js.ArrowFunc, *js.FuncDecl:
+		default:
+			return nil
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+// File: pkg/module/federation.go
+package module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Manifests emitted by Module Federation 2.0, in order of preference.
+var federationManifests = []string{"mf-manifest.json", "mf-stats.json"}
+
+// federationManifest is the subset of mf-manifest.json and mf-stats.json the
+// operator reads. Both files share this layout.
+type federationManifest struct {
+	// dir is the manifest's directory relative to the bundle root; asset
+	// paths are relative to it.
+	dir string
+
+	Shared  []federationShared `json:"shared"`
+	Exposes []struct {
+		Path string `json:"path"`
+	} `json:"exposes"`
+}
+
+type federationShared struct {
+	Name            string          `json:"name"`
+	Version         string          `json:"version"`
+	RequiredVersion json.RawMessage `json:"requiredVersion"`
+	Singleton       bool            `json:"singleton"`
+	Eager           bool            `json:"eager"`
+	StrictVersion   bool            `json:"strictVersion"`
+	ShareScope      json.RawMessage `json:"shareScope"`
+	Assets          struct {
+		JS  federationAssets `json:"js"`
+		CSS federationAssets `json:"css"`
+	} `json:"assets"`
+}
+
+type federationAssets struct {
+	Sync  []string `json:"sync"`
+	Async []string `json:"async"`
+}
+
+// readFederationManifest loads the first federation manifest found next to
+// the entry point or at the bundle root. It returns nil if there is none.
+func readFederationManifest(bundlePath, entry string) (*federationManifest, error) {
+	dirs := []string{path.Dir(entry)}
+	if dirs[0] != "." {
+		dirs = append(dirs, ".")
+	}
+	for _, dir := range dirs {
+		for _, name := range federationManifests {
+			data, err := os.ReadFile(filepath.Join(bundlePath, filepath.FromSlash(dir), name))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			m := federationManifest{dir: dir}
+			if err := json.Unmarshal(data, &m); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *federationManifest) sharedModules(entry string) ([]SharedModule, error) {
+	modules := make([]SharedModule, 0, len(m.Shared))
+	for _, s := range m.Shared {
+		files, err := m.assetFiles(s)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, SharedModule{
+			Name:            s.Name,
+			Version:         s.Version,
+			Entry:           entry,
+			RequiredVersion: rawString(s.RequiredVersion),
+			Singleton:       s.Singleton,
+			Eager:           s.Eager,
+			StrictVersion:   s.StrictVersion,
+			ShareScope:      shareScopeName(s.ShareScope),
+			Files:           files,
+		})
+	}
+	return modules, nil
+}
+
+// assetFiles returns the chunk files of a shared module relative to the
+// bundle root. Absolute paths and paths leading out of the bundle are
+// rejected.
+func (m *federationManifest) assetFiles(s federationShared) ([]string, error) {
+	var files []string
+	for _, list := range [][]string{s.Assets.JS.Sync, s.Assets.JS.Async, s.Assets.CSS.Sync, s.Assets.CSS.Async} {
+		for _, f := range list {
+			file := path.Join(m.dir, f)
+			if path.IsAbs(f) || !inBundle(file) {
+				return nil, fmt.Errorf("%w: %s asset %q is outside the bundle", ErrInvalidSharedModule, s.Name, f)
+			}
+			files = append(files, file)
+		}
+	}
+	return sortedUnique(files), nil
+}
+
+// rawString returns raw if it is a JSON string; requiredVersion may also be
+// false, meaning any version is accepted.
+func rawString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return ""
+	}
+	return s
+}
+
+// shareScopeName returns the share scope, which may be a string or a list of
+// scopes of which the first is used.
+func shareScopeName(raw json.RawMessage) string {
+	if s := rawString(raw); s != "" {
+		return s
+	}
+	var scopes []string
+	if json.Unmarshal(raw, &scopes) == nil && len(scopes) > 0 {
+		return scopes[0]
+	}
+	return ""
+}
+
+func (m *federationManifest) exposes() []string {
+	exposes := make([]string, 0, len(m.Exposes))
+	for _, e := range m.Exposes {
+		exposes = append(exposes, e.Path)
+	}
+	return exposes
+}
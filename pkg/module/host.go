@@ -0,0 +1,51 @@
+// File: pkg/module/host.go
+package module
+
+import (
+	"github.com/Masterminds/semver/v3"
+)
+
+// HostManifestFile is the name of the manifest published for a host.
+const HostManifestFile = "host-manifest.json"
+
+// HostManifest maps each remote a host loads to its entry, along with the
+// singletons the host provides
+type HostManifest struct {
+	Remotes map[string]HostManifestRemote `json:"remotes"`
+	Shared  map[string]HostManifestShared `json:"shared,omitempty"`
+}
+
+// HostManifestRemote is a remote entry in a HostManifest
+type HostManifestRemote struct {
+	URL     string   `json:"url"`
+	Version string   `json:"version,omitempty"`
+	Digest  string   `json:"digest,omitempty"`
+	Exposes []string `json:"exposes,omitempty"`
+
+	// Versions splits traffic between the versions of a remote under
+	// rollout; URL is its stable version.
+	Versions []WeightedRemote `json:"versions,omitempty"`
+}
+
+// HostManifestShared is a shared module the host provides
+type HostManifestShared struct {
+	Version   string `json:"version"`
+	Singleton bool   `json:"singleton"`
+}
+
+// AcceptsVersion reports whether a bundle sharing m runs against version of
+// the module provided by its host. m's RequiredVersion range decides when
+// set; otherwise the versions must be on the same release line.
+func AcceptsVersion(m SharedModule, version string) bool {
+	if m.RequiredVersion != "" {
+		c, err := semver.NewConstraint(m.RequiredVersion)
+		v, verr := semver.NewVersion(version)
+		if err == nil && verr == nil {
+			return c.Check(v)
+		}
+	}
+	if m.Version == "" {
+		return true
+	}
+	return releaseLine(m.Version) == releaseLine(version)
+}
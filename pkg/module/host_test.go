@@ -0,0 +1,19 @@
+// File: pkg/module/host_test.go
+package module_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mfe-operator/pkg/module"
+)
+
+func TestAcceptsVersion(t *testing.T) {
+	assert.True(t, module.AcceptsVersion(module.SharedModule{Name: "react", Version: "18.2.0", RequiredVersion: "^18.0.0"}, "18.3.1"))
+	assert.False(t, module.AcceptsVersion(module.SharedModule{Name: "react", Version: "18.2.0", RequiredVersion: "~18.2.0"}, "18.3.1"))
+	// Without a range the release lines must match
+	assert.True(t, module.AcceptsVersion(module.SharedModule{Name: "react", Version: "18.2.0"}, "18.3.1"))
+	assert.False(t, module.AcceptsVersion(module.SharedModule{Name: "react", Version: "17.0.2"}, "18.3.1"))
+	assert.False(t, module.AcceptsVersion(module.SharedModule{Name: "zod", Version: "0.9.0"}, "0.10.0"))
+}
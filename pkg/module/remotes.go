@@ -0,0 +1,66 @@
+// File: pkg/module/remotes.go
+package module
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// File names of the generated remote catalogs. CatalogFile points at a
+// matching pair of the other two.
+const (
+	RemotesFile   = "remotes.json"
+	ImportMapFile = "importmap.json"
+	CatalogFile   = "catalog.json"
+)
+
+// Remote describes a published federation container for host applications
+type Remote struct {
+	Name string `json:"name"`
+	// URL is the public URL of the container's entry script.
+	URL     string   `json:"url"`
+	Digest  string   `json:"digest,omitempty"`
+	Exposes []string `json:"exposes,omitempty"`
+}
+
+// RemotesManifest is the content of remotes.json
+type RemotesManifest struct {
+	Remotes []Remote `json:"remotes"`
+}
+
+// ImportMap is the content of importmap.json, mapping each remote name to
+// its entry URL
+type ImportMap struct {
+	Imports map[string]string `json:"imports"`
+}
+
+// CatalogPointer is the content of catalog.json. It is written after the
+// files it names, which never change once written, so a host that reads
+// them through it always gets a remotes.json and importmap.json that match.
+type CatalogPointer struct {
+	// Hash identifies the content of both files.
+	Hash string `json:"hash"`
+	// Remotes and ImportMap are the paths of the files, relative to
+	// catalog.json.
+	Remotes   string `json:"remotes"`
+	ImportMap string `json:"importMap"`
+}
+
+// RenderCatalog returns remotes.json and importmap.json for remotes, sorted
+// by name so that unchanged input renders identically.
+func RenderCatalog(remotes []Remote) (remotesJSON, importMapJSON []byte, err error) {
+	sorted := append([]Remote{}, remotes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	importMap := ImportMap{Imports: map[string]string{}}
+	for _, r := range sorted {
+		importMap.Imports[r.Name] = r.URL
+	}
+	if remotesJSON, err = json.MarshalIndent(RemotesManifest{Remotes: sorted}, "", "  "); err != nil {
+		return nil, nil, err
+	}
+	if importMapJSON, err = json.MarshalIndent(importMap, "", "  "); err != nil {
+		return nil, nil, err
+	}
+	return remotesJSON, importMapJSON, nil
+}
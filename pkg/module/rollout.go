@@ -0,0 +1,37 @@
+// File: pkg/module/rollout.go
+package module
+
+// RolloutManifestFile is the name of the manifest published next to the
+// versions of a MicroFrontend under rollout.
+const RolloutManifestFile = "rollout.json"
+
+// RolloutManifest lists the published versions of a remote and the share
+// of users each one is served to
+type RolloutManifest struct {
+	Versions []WeightedRemote `json:"versions"`
+}
+
+// WeightedRemote is a version of a remote served to Weight percent of users
+type WeightedRemote struct {
+	URL    string `json:"url"`
+	Digest string `json:"digest,omitempty"`
+	Weight int32  `json:"weight"`
+}
+
+// SplitTraffic sends weight percent of users to canary and the rest to
+// stable. Versions left without traffic or without a URL are omitted.
+func SplitTraffic(stable, canary WeightedRemote, weight int32) []WeightedRemote {
+	if weight < 0 {
+		weight = 0
+	} else if weight > 100 {
+		weight = 100
+	}
+	stable.Weight, canary.Weight = 100-weight, weight
+	var versions []WeightedRemote
+	for _, v := range []WeightedRemote{stable, canary} {
+		if v.Weight > 0 && v.URL != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,25 @@
+// File: pkg/module/rollout_test.go
+package module_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mfe-operator/pkg/module"
+)
+
+func TestSplitTraffic(t *testing.T) {
+	stable := module.WeightedRemote{URL: "https://cdn.example.com/checkout/versions/aaa/remoteEntry.js", Digest: "sha256:aaa"}
+	canary := module.WeightedRemote{URL: "https://cdn.example.com/checkout/versions/bbb/remoteEntry.js", Digest: "sha256:bbb"}
+
+	assert.Equal(t, []module.WeightedRemote{
+		{URL: stable.URL, Digest: stable.Digest, Weight: 90},
+		{URL: canary.URL, Digest: canary.Digest, Weight: 10},
+	}, module.SplitTraffic(stable, canary, 10))
+
+	// Versions without traffic are left out
+	assert.Equal(t, []module.WeightedRemote{{URL: stable.URL, Digest: stable.Digest, Weight: 100}}, module.SplitTraffic(stable, canary, 0))
+	assert.Equal(t, []module.WeightedRemote{{URL: canary.URL, Digest: canary.Digest, Weight: 100}}, module.SplitTraffic(stable, canary, 100))
+	assert.Equal(t, []module.WeightedRemote{{URL: stable.URL, Digest: stable.Digest, Weight: 100}}, module.SplitTraffic(stable, module.WeightedRemote{}, 0))
+}
@@ -3,76 +3,215 @@ package module
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
 	"mfe-operator/pkg/bundle/cdn"
 )
 
-// SharedModule represents a JS module shared by an MFE
+// SharedModule represents a JS module shared by an MFE, with its share config
 type SharedModule struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 	Entry   string `json:"entry"`
+
+	// RequiredVersion is the semver range consumers accept, e.g. "^18.2.0".
+	RequiredVersion string `json:"requiredVersion,omitempty"`
+	Singleton       bool   `json:"singleton,omitempty"`
+	Eager           bool   `json:"eager,omitempty"`
+	StrictVersion   bool   `json:"strictVersion,omitempty"`
+	ShareScope      string `json:"shareScope,omitempty"`
+
+	// Files are the chunk files holding the module's code, relative to the
+	// bundle root. Eager modules are compiled into the entry and have none.
+	Files []string `json:"files,omitempty"`
+}
+
+// AnalyzeSharedModules finds the modules an extracted bundle shares. entry is
+// the resolved entry point (see ResolveEntryPoint). It reads the Module
+// Federation 2.0 manifest (mf-manifest.json or mf-stats.json) when the bundle
+// has one, and otherwise parses the share scope out of the entry script.
+func AnalyzeSharedModules(bundlePath, entry string) ([]SharedModule, error) {
+	manifest, err := readFederationManifest(bundlePath, entry)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		modules, err := manifest.sharedModules(entry)
+		if err != nil {
+			return nil, err
+		}
+		return dedupe(modules), nil
+	}
+
+	decls, err := parseShareScope(filepath.Join(bundlePath, filepath.FromSlash(entry)))
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := chunkFiles(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	modules := make([]SharedModule, 0, len(decls))
+	for _, d := range decls {
+		m := d.SharedModule
+		m.Entry = entry
+		for _, id := range d.chunks {
+			m.Files = append(m.Files, chunks[id]...)
+		}
+		m.Files = sortedUnique(m.Files)
+		modules = append(modules, m)
+	}
+	return dedupe(modules), nil
 }
 
-// AnalyzeSharedModules scans an extracted bundle directory to find shared modules
-func AnalyzeSharedModules(bundlePath string) ([]SharedModule, error) {
-	var modules []SharedModule
+// chunkFile matches webpack chunk file names: the chunk id, optionally
+// followed by a name or content hash.
+var chunkFile = regexp.MustCompile(`^([^.]+)(\..+)?\.(js|css)$`)
+
+// chunkFiles maps chunk ids to the files in the bundle that hold them.
+func chunkFiles(bundlePath string) (map[string][]string, error) {
+	files := map[string][]string{}
 	err := filepath.WalkDir(bundlePath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
 			return err
 		}
-		if strings.HasSuffix(d.Name(), ".js") {
-			matched, _ := filepath.Match("remoteEntry.js", d.Name())
-			if matched {
-				found, err := parseRemoteEntryForShared(path)
-				if err != nil {
-					return err
-				}
-				modules = append(modules, found...)
-			}
+		match := chunkFile.FindStringSubmatch(d.Name())
+		if match == nil {
+			return nil
+		}
+		rel, err := filepath.Rel(bundlePath, path)
+		if err != nil {
+			return err
 		}
+		files[match[1]] = append(files[match[1]], filepath.ToSlash(rel))
 		return nil
 	})
-	return modules, err
+	return files, err
 }
 
-// Very simple shared module detection from remoteEntry.js
-func parseRemoteEntryForShared(path string) ([]SharedModule, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// dedupe keeps the first occurrence of each name@version.
+func dedupe(modules []SharedModule) []SharedModule {
+	seen := map[string]bool{}
+	out := modules[:0]
+	for _, m := range modules {
+		key := m.Name + "@" + m.Version
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// ErrInvalidSharedModule is returned for shared modules that cannot be
+// published safely: names that are not npm package names, versions that are
+// not exact semver versions, and files outside the bundle.
+var ErrInvalidSharedModule = errors.New("invalid shared module")
+
+// packageName matches npm package names, optionally scoped.
+var packageName = regexp.MustCompile(`^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+
+// maxPackageName is the longest package name npm accepts.
+const maxPackageName = 214
+
+// ValidateSharedModule checks that m can be published under VendorPath: its
+// name must be an npm package name, its version an exact semver version
+// and its files relative paths inside the bundle.
+func ValidateSharedModule(m SharedModule) error {
+	if len(m.Name) > maxPackageName || !packageName.MatchString(m.Name) {
+		return fmt.Errorf("%w: %q is not a package name", ErrInvalidSharedModule, m.Name)
 	}
-	// naive regex to catch e.g. react@18.2.0 references
-	re := regexp.MustCompile(`"([a-zA-Z0-9-_]+)@([0-9]+\.[0-9]+\.[0-9]+)"`)
-	matches := re.FindAllStringSubmatch(string(data), -1)
-	modules := make([]SharedModule, 0, len(matches))
-	for _, match := range matches {
-		modules = append(modules, SharedModule{
-			Name:    match[1],
-			Version: match[2],
-			Entry:   filepath.Base(path),
-		})
+	if _, err := semver.StrictNewVersion(m.Version); err != nil {
+		return fmt.Errorf("%w: %s version %q is not a semver version", ErrInvalidSharedModule, m.Name, m.Version)
 	}
-	return modules, nil
+	for _, file := range m.Files {
+		if path.IsAbs(file) || !inBundle(file) {
+			return fmt.Errorf("%w: %s file %q is outside the bundle", ErrInvalidSharedModule, m.Name, file)
+		}
+	}
+	return nil
 }
 
-// UploadSharedModules uploads each module to the CDN under vendor/<name>@<version>/<entry>
-func UploadSharedModules(ctx context.Context, uploader cdn.CDNClient, bundlePath string, modules []SharedModule) error {
+// inBundle reports whether the slash-separated relative path p stays inside
+// the directory it is relative to.
+func inBundle(p string) bool {
+	p = path.Clean(p)
+	return p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// VendorPath returns the CDN prefix a shared module version is published
+// under, vendor/<name>@<version>. Callers check m with ValidateSharedModule
+// first.
+func VendorPath(m SharedModule) string {
+	return fmt.Sprintf("vendor/%s@%s", m.Name, m.Version)
+}
+
+// ContentHash returns a "sha256:<hex>" digest over the module's chunk files,
+// their paths included, so that two bundles carrying the same build of a
+// version hash identically. m must pass ValidateSharedModule.
+func ContentHash(bundlePath string, m SharedModule) (string, error) {
+	if err := ValidateSharedModule(m); err != nil {
+		return "", err
+	}
+	files := append([]string(nil), m.Files...)
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(bundlePath, filepath.FromSlash(file)))
+		if err != nil {
+			return "", fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		fmt.Fprintf(h, "%x  %s\n", fh.Sum(nil), file)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// UploadSharedModules uploads the chunk files of each module under its
+// VendorPath, keeping their paths relative to the bundle root. It returns
+// the modules that were published; modules without chunk files, such as
+// eager ones, are skipped.
+func UploadSharedModules(ctx context.Context, uploader cdn.CDNClient, bundlePath string, modules []SharedModule) ([]SharedModule, error) {
+	var published []SharedModule
 	for _, m := range modules {
-		srcPath := filepath.Join(bundlePath, m.Entry)
-		remotePath := fmt.Sprintf("vendor/%s@%s/%s", m.Name, m.Version, m.Entry)
-		if err := uploader.Upload(ctx, srcPath, remotePath); err != nil {
-			return fmt.Errorf("uploading module %s: %w", m.Name, err)
+		if len(m.Files) == 0 {
+			log.FromContext(ctx).V(1).Info("Skipping shared module without chunk files", "module", m.Name+"@"+m.Version)
+			continue
+		}
+		if err := ValidateSharedModule(m); err != nil {
+			return published, err
 		}
+		for _, file := range m.Files {
+			srcPath := filepath.Join(bundlePath, filepath.FromSlash(file))
+			remotePath := VendorPath(m) + "/" + file
+			if err := uploader.Upload(ctx, srcPath, remotePath); err != nil {
+				return published, fmt.Errorf("uploading module %s: %w", m.Name, err)
+			}
+		}
+		published = append(published, m)
 	}
-	return nil
+	return published, nil
 }
 
 // SaveSharedModulesManifest saves shared modules metadata to a JSON file
@@ -0,0 +1,218 @@
+// File: pkg/module/shared_test.go
+package module_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/module"
+)
+
+func writeBundle(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+	}
+	return dir
+}
+
+func TestAnalyzeSharedModulesFromManifest(t *testing.T) {
+	dir := writeBundle(t, map[string]string{
+		"static/mf-manifest.json": `{
+			"shared": [
+				{"name": "@mui/material", "version": "5.15.0-beta.1", "requiredVersion": "^5.14.0", "singleton": false, "shareScope": "default"},
+				{"name": "react", "version": "18.2.0", "requiredVersion": "^18.2.0", "singleton": true, "eager": true, "shareScope": ["default", "legacy"],
+				 "assets": {"js": {"sync": ["__federation_shared_react.js"], "async": ["js/935.e1f2.js"]}, "css": {"sync": [], "async": []}}},
+				{"name": "lodash", "version": "4.17.21", "requiredVersion": false}
+			]
+		}`,
+		// Ignored in favour of the manifest
+		"static/remoteEntry.3f2a.js": `register("left-pad", "1.3.0", () => 1);`,
+	})
+
+	entry, err := module.ResolveEntryPoint(dir, "static/remoteEntry.js")
+	require.NoError(t, err)
+	modules, err := module.AnalyzeSharedModules(dir, entry)
+	require.NoError(t, err)
+	assert.Equal(t, []module.SharedModule{
+		{Name: "@mui/material", Version: "5.15.0-beta.1", Entry: "static/remoteEntry.3f2a.js", RequiredVersion: "^5.14.0", ShareScope: "default"},
+		{Name: "react", Version: "18.2.0", Entry: "static/remoteEntry.3f2a.js", RequiredVersion: "^18.2.0", Singleton: true, Eager: true, ShareScope: "default",
+			Files: []string{"static/__federation_shared_react.js", "static/js/935.e1f2.js"}},
+		{Name: "lodash", Version: "4.17.21", Entry: "static/remoteEntry.3f2a.js"},
+	}, modules)
+}
+
+func TestAnalyzeSharedModulesFromWebpackShareScope(t *testing.T) {
+	// Minified webpack 5 __webpack_require__.I output
+	dir := writeBundle(t, map[string]string{
+		"js/remoteEntry.js": `var a={};a.I=(e,r)=>{var t=a.S[e],o=(e,r,o,n)=>{var i=t[e]=t[e]||{};i[r]={get:o,eager:!!n}};` +
+			`"default"===e&&(o("@mui/material","5.15.0-beta.1",(()=>Promise.all([a.e(1)]).then((()=>()=>a(7))))),` +
+			`o("react","18.2.0",(()=>()=>a(294)),!0),o("react","18.2.0",(()=>()=>a(294)),1));` +
+			`console.log("not-a-module","1.2.3");f("also-not","1.0",()=>1)};`,
+		"js/1.8d3c.js":  "mui",
+		"js/1.8d3c.css": "mui styles",
+		"js/10.a1b2.js": "other chunk",
+	})
+
+	modules, err := module.AnalyzeSharedModules(dir, "js/remoteEntry.js")
+	require.NoError(t, err)
+	assert.Equal(t, []module.SharedModule{
+		{Name: "@mui/material", Version: "5.15.0-beta.1", Entry: "js/remoteEntry.js", Files: []string{"js/1.8d3c.css", "js/1.8d3c.js"}},
+		{Name: "react", Version: "18.2.0", Entry: "js/remoteEntry.js", Eager: true},
+	}, modules)
+}
+
+func TestAnalyzeSharedModulesFromRuntimeOptions(t *testing.T) {
+	// Module Federation 2.0 runtime init options
+	dir := writeBundle(t, map[string]string{
+		"remoteEntry.js": `const usedShared={react:{name:"react",version:"18.3.1",scope:["default"],get:async()=>()=>r(1),` +
+			`shareConfig:{singleton:!0,requiredVersion:"^18.0.0",eager:!1,strictVersion:!1}},` +
+			`"react-dom":[{version:"18.3.1",get:()=>r(2),scope:"default",shareConfig:{singleton:true,requiredVersion:"^18.0.0"}}]};` +
+			`const other={version:"1.0.0",name:"unrelated"};`,
+	})
+
+	modules, err := module.AnalyzeSharedModules(dir, "remoteEntry.js")
+	require.NoError(t, err)
+	assert.Equal(t, []module.SharedModule{
+		{Name: "react", Version: "18.3.1", Entry: "remoteEntry.js", RequiredVersion: "^18.0.0", Singleton: true, ShareScope: "default"},
+		{Name: "react-dom", Version: "18.3.1", Entry: "remoteEntry.js", RequiredVersion: "^18.0.0", Singleton: true, ShareScope: "default"},
+	}, modules)
+}
+
+func TestDiscoverExposesFromManifest(t *testing.T) {
+	dir := writeBundle(t, map[string]string{
+		"mf-manifest.json": `{"exposes": [{"name": "Cart", "path": "./Cart"}, {"name": "MiniCart", "path": "./widgets/MiniCart"}]}`,
+	})
+	exposes, err := module.DiscoverExposes(dir, "remoteEntry.js")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"./Cart", "./widgets/MiniCart"}, exposes)
+}
+
+func TestDiscoverExposesFromRemoteEntry(t *testing.T) {
+	dir := writeBundle(t, map[string]string{
+		"remoteEntry.js": `var s={"./widgets/MiniCart":()=>Promise.all([t.e(2)]).then((()=>()=>t(9))),"./Cart":function(){return t(3)}},` +
+			`c={"./not-exposed":"chunk.js"};`,
+	})
+	exposes, err := module.DiscoverExposes(dir, "remoteEntry.js")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"./Cart", "./widgets/MiniCart"}, exposes)
+}
+
+func TestCompareExposes(t *testing.T) {
+	missing, extra := module.CompareExposes([]string{"./Cart", "./Checkout"}, []string{"./Cart", "./MiniCart"})
+	assert.Equal(t, []string{"./Checkout"}, missing)
+	assert.Equal(t, []string{"./MiniCart"}, extra)
+}
+
+type mockCDNClient struct {
+	mock.Mock
+}
+
+func (m *mockCDNClient) Upload(ctx context.Context, localPath, remotePath string) error {
+	return m.Called(localPath, remotePath).Error(0)
+}
+
+func TestUploadSharedModulesUploadsChunks(t *testing.T) {
+	dir := writeBundle(t, map[string]string{"js/935.e1f2.js": "react", "js/1.8d3c.js": "mui"})
+	modules := []module.SharedModule{
+		{Name: "react", Version: "18.2.0", Entry: "remoteEntry.js", Files: []string{"js/935.e1f2.js"}},
+		{Name: "@mui/material", Version: "5.15.0", Entry: "remoteEntry.js", Files: []string{"js/1.8d3c.js"}},
+		{Name: "lodash", Version: "4.17.21", Entry: "remoteEntry.js", Eager: true},
+	}
+
+	client := new(mockCDNClient)
+	client.On("Upload", filepath.Join(dir, "js", "935.e1f2.js"), "vendor/react@18.2.0/js/935.e1f2.js").Return(nil)
+	client.On("Upload", filepath.Join(dir, "js", "1.8d3c.js"), "vendor/@mui/material@5.15.0/js/1.8d3c.js").Return(nil)
+
+	published, err := module.UploadSharedModules(context.Background(), client, dir, modules)
+	require.NoError(t, err)
+	assert.Equal(t, modules[:2], published)
+	client.AssertExpectations(t)
+}
+
+func TestContentHash(t *testing.T) {
+	a := writeBundle(t, map[string]string{"js/935.e1f2.js": "react", "js/1.8d3c.js": "mui"})
+	b := writeBundle(t, map[string]string{"js/935.e1f2.js": "react", "js/1.8d3c.js": "mui", "unrelated.js": "x"})
+	c := writeBundle(t, map[string]string{"js/935.e1f2.js": "react (patched)", "js/1.8d3c.js": "mui"})
+	m := module.SharedModule{Name: "react", Version: "18.2.0", Files: []string{"js/935.e1f2.js", "js/1.8d3c.js"}}
+
+	hashA, err := module.ContentHash(a, m)
+	require.NoError(t, err)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, hashA)
+
+	// File order and files outside the module do not matter
+	reordered := m
+	reordered.Files = []string{"js/1.8d3c.js", "js/935.e1f2.js"}
+	hashB, err := module.ContentHash(b, reordered)
+	require.NoError(t, err)
+	assert.Equal(t, hashA, hashB)
+
+	hashC, err := module.ContentHash(c, m)
+	require.NoError(t, err)
+	assert.NotEqual(t, hashA, hashC)
+
+	_, err = module.ContentHash(t.TempDir(), m)
+	assert.Error(t, err)
+}
+
+func TestAnalyzeSharedModulesRejectsAssetsOutsideBundle(t *testing.T) {
+	for _, asset := range []string{"../secret", "js/../../secret", "/etc/passwd"} {
+		dir := writeBundle(t, map[string]string{
+			"mf-manifest.json": `{"shared": [{"name": "react", "version": "18.2.0",
+				"assets": {"js": {"sync": ["js/935.e1f2.js", "` + asset + `"]}}}]}`,
+			"remoteEntry.js": "",
+		})
+
+		_, err := module.AnalyzeSharedModules(dir, "remoteEntry.js")
+		assert.ErrorIs(t, err, module.ErrInvalidSharedModule, asset)
+	}
+}
+
+func TestValidateSharedModule(t *testing.T) {
+	tests := []struct {
+		name   string
+		module module.SharedModule
+		valid  bool
+	}{
+		{"plain", module.SharedModule{Name: "react", Version: "18.2.0", Files: []string{"js/935.e1f2.js"}}, true},
+		{"scoped prerelease", module.SharedModule{Name: "@mui/material", Version: "5.15.0-beta.1+build.7"}, true},
+		{"name escapes vendor", module.SharedModule{Name: "../../x", Version: "1.0.0"}, false},
+		{"scope escapes vendor", module.SharedModule{Name: "@../x", Version: "1.0.0"}, false},
+		{"name with slash", module.SharedModule{Name: "react/../../x", Version: "1.0.0"}, false},
+		{"uppercase name", module.SharedModule{Name: "React", Version: "1.0.0"}, false},
+		{"version escapes vendor", module.SharedModule{Name: "react", Version: "1.0.0/../../../x"}, false},
+		{"version range", module.SharedModule{Name: "react", Version: "^18.2.0"}, false},
+		{"empty version", module.SharedModule{Name: "react"}, false},
+		{"file escapes bundle", module.SharedModule{Name: "react", Version: "18.2.0", Files: []string{"js/../../secret"}}, false},
+		{"absolute file", module.SharedModule{Name: "react", Version: "18.2.0", Files: []string{"/etc/passwd"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := module.ValidateSharedModule(tt.module)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, module.ErrInvalidSharedModule)
+			}
+		})
+	}
+}
+
+func TestUploadSharedModulesRejectsInvalidModules(t *testing.T) {
+	dir := writeBundle(t, map[string]string{"js/935.e1f2.js": "react"})
+	client := new(mockCDNClient)
+
+	_, err := module.UploadSharedModules(context.Background(), client, dir, []module.SharedModule{
+		{Name: "../../x", Version: "1.0.0", Files: []string{"js/935.e1f2.js"}},
+	})
+	assert.ErrorIs(t, err, module.ErrInvalidSharedModule)
+	client.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,249 @@
+// File: pkg/module/sharescope.go
+package module
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/js"
+)
+
+// parseShareScope extracts the shared modules a remote entry registers. It
+// parses the script and recognizes two shapes that survive minification:
+//
+//   - webpack 5 share scope registration, register("react", "18.2.0", factory, eager)
+//   - Module Federation 2.0 runtime options, {react: [{version: "18.2.0", shareConfig: {...}}]}
+//
+// Along with each module it returns the ids of the chunks its factory loads.
+func parseShareScope(path string) ([]sharedDecl, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ast, err := js.Parse(parse.NewInputBytes(data), js.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	v := &shareScopeVisitor{}
+	js.Walk(v, ast)
+	return v.modules, nil
+}
+
+// sharedDecl is a shared module found in a remote entry and the ids of the
+// chunks that hold its code.
+type sharedDecl struct {
+	SharedModule
+	chunks []string
+}
+
+type shareScopeVisitor struct {
+	modules []sharedDecl
+}
+
+func (v *shareScopeVisitor) Enter(n js.INode) js.IVisitor {
+	switch n := n.(type) {
+	case *js.CallExpr:
+		if m, ok := registerCall(n); ok {
+			v.modules = append(v.modules, m)
+		}
+	case *js.ObjectExpr:
+		v.modules = append(v.modules, sharedOptions(n)...)
+	}
+	return v
+}
+
+func (v *shareScopeVisitor) Exit(js.INode) {}
+
+// registerCall matches a call with a package name, an exact version and a
+// factory function, optionally followed by the eager flag.
+func registerCall(call *js.CallExpr) (sharedDecl, bool) {
+	args := call.Args.List
+	if len(args) < 3 {
+		return sharedDecl{}, false
+	}
+	name, ok := stringLiteral(args[0].Value)
+	if !ok || name == "" {
+		return sharedDecl{}, false
+	}
+	version, ok := stringLiteral(args[1].Value)
+	if !ok {
+		return sharedDecl{}, false
+	}
+	if _, err := semver.StrictNewVersion(version); err != nil {
+		return sharedDecl{}, false
+	}
+	switch unparen(args[2].Value).(type) {
+	case *js.ArrowFunc, *js.FuncDecl:
+	default:
+		return sharedDecl{}, false
+	}
+	d := sharedDecl{SharedModule: SharedModule{Name: name, Version: version}, chunks: chunkIDs(args[2].Value)}
+	if len(args) > 3 {
+		d.Eager = truthy(args[3].Value)
+	}
+	return d, true
+}
+
+// chunkIDs returns the ids of the chunks a factory loads through
+// __webpack_require__.e, which minifies to calls like a.e(935).
+func chunkIDs(factory js.INode) []string {
+	v := &chunkVisitor{}
+	js.Walk(v, factory)
+	return v.ids
+}
+
+type chunkVisitor struct {
+	ids []string
+}
+
+func (v *chunkVisitor) Enter(n js.INode) js.IVisitor {
+	call, ok := n.(*js.CallExpr)
+	if !ok || len(call.Args.List) != 1 {
+		return v
+	}
+	if dot, ok := call.X.(*js.DotExpr); !ok || string(dot.Y.Data) != "e" {
+		return v
+	}
+	lit, ok := call.Args.List[0].Value.(*js.LiteralExpr)
+	if !ok {
+		return v
+	}
+	switch lit.TokenType {
+	case js.DecimalToken, js.IntegerToken:
+		v.ids = append(v.ids, string(lit.Data))
+	case js.StringToken:
+		id, _ := stringLiteral(lit)
+		v.ids = append(v.ids, id)
+	}
+	return v
+}
+
+func (v *chunkVisitor) Exit(js.INode) {}
+
+// sharedOptions matches an object mapping package names to share
+// configurations, or lists of them, that carry a version and a getter or
+// shareConfig.
+func sharedOptions(obj *js.ObjectExpr) []sharedDecl {
+	var modules []sharedDecl
+	for _, prop := range obj.List {
+		name, ok := propertyKey(prop)
+		if !ok {
+			continue
+		}
+		var configs []*js.ObjectExpr
+		switch value := prop.Value.(type) {
+		case *js.ObjectExpr:
+			configs = append(configs, value)
+		case *js.ArrayExpr:
+			for _, el := range value.List {
+				if o, ok := el.Value.(*js.ObjectExpr); ok {
+					configs = append(configs, o)
+				}
+			}
+		}
+		for _, cfg := range configs {
+			if m, ok := sharedConfig(name, cfg); ok {
+				modules = append(modules, m)
+			}
+		}
+	}
+	return modules
+}
+
+func sharedConfig(name string, cfg *js.ObjectExpr) (sharedDecl, bool) {
+	props := objectProperties(cfg)
+	version, ok := stringLiteral(props["version"])
+	if !ok {
+		return sharedDecl{}, false
+	}
+	if props["get"] == nil && props["shareConfig"] == nil {
+		return sharedDecl{}, false
+	}
+	m := sharedDecl{SharedModule: SharedModule{Name: name, Version: version}}
+	if get := props["get"]; get != nil {
+		m.chunks = chunkIDs(get)
+	}
+	switch scope := props["scope"].(type) {
+	case *js.ArrayExpr:
+		if len(scope.List) > 0 {
+			m.ShareScope, _ = stringLiteral(scope.List[0].Value)
+		}
+	default:
+		m.ShareScope, _ = stringLiteral(scope)
+	}
+	if sc, ok := props["shareConfig"].(*js.ObjectExpr); ok {
+		config := objectProperties(sc)
+		m.Singleton = truthy(config["singleton"])
+		m.Eager = truthy(config["eager"])
+		m.StrictVersion = truthy(config["strictVersion"])
+		m.RequiredVersion, _ = stringLiteral(config["requiredVersion"])
+	}
+	return m, true
+}
+
+func objectProperties(obj *js.ObjectExpr) map[string]js.IExpr {
+	props := map[string]js.IExpr{}
+	for _, prop := range obj.List {
+		if key, ok := propertyKey(prop); ok {
+			props[key] = prop.Value
+		}
+	}
+	return props
+}
+
+// propertyKey returns the literal key of an object property.
+func propertyKey(prop js.Property) (string, bool) {
+	if prop.Name == nil || prop.Name.IsComputed() || prop.Spread {
+		return "", false
+	}
+	lit := prop.Name.Literal
+	if lit.TokenType == js.StringToken {
+		return stringLiteral(&lit)
+	}
+	if len(lit.Data) == 0 {
+		return "", false
+	}
+	return string(lit.Data), true
+}
+
+// stringLiteral returns the value of a quoted string literal.
+func stringLiteral(e js.IExpr) (string, bool) {
+	lit, ok := e.(*js.LiteralExpr)
+	if !ok || lit.TokenType != js.StringToken || len(lit.Data) < 2 {
+		return "", false
+	}
+	return string(lit.Data[1 : len(lit.Data)-1]), true
+}
+
+// unparen strips the parentheses minifiers wrap around expressions.
+func unparen(e js.IExpr) js.IExpr {
+	for {
+		g, ok := e.(*js.GroupExpr)
+		if !ok {
+			return e
+		}
+		e = g.X
+	}
+}
+
+// truthy evaluates the literal forms minifiers emit for booleans: true, !0,
+// and non-zero numbers.
+func truthy(e js.IExpr) bool {
+	switch e := unparen(e).(type) {
+	case *js.LiteralExpr:
+		switch e.TokenType {
+		case js.TrueToken:
+			return true
+		case js.DecimalToken, js.IntegerToken:
+			return string(e.Data) != "0"
+		}
+	case *js.UnaryExpr:
+		if lit, ok := e.X.(*js.LiteralExpr); ok && e.Op == js.NotToken {
+			return !truthy(lit)
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+// File: pkg/module/skew.go
+package module
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Consumer is a MicroFrontend together with the shared modules it declares
+type Consumer struct {
+	// Name identifies the MicroFrontend, e.g. "namespace/name".
+	Name    string
+	Modules []SharedModule
+}
+
+// VersionUsage lists the consumers of one version of a shared module
+type VersionUsage struct {
+	Version   string
+	Consumers []string
+}
+
+// ModuleSkew describes the versions of a shared module in use across consumers
+type ModuleSkew struct {
+	Name string
+	// Singleton is set when any consumer shares the module as a singleton.
+	Singleton bool
+	// Versions are sorted from lowest to highest.
+	Versions []VersionUsage
+	// Lines is the number of mutually incompatible release lines in use:
+	// distinct major versions, or minor versions below 1.0.0.
+	Lines int
+}
+
+// Conflict reports whether a singleton module is in use at incompatible
+// versions. Only one copy is loaded at runtime, so some consumers get a
+// version they were not built against.
+func (s ModuleSkew) Conflict() bool {
+	return s.Singleton && s.Lines > 1
+}
+
+// Consumers returns the number of consumers across all versions.
+func (s ModuleSkew) Consumers() int {
+	n := 0
+	for _, v := range s.Versions {
+		n += len(v.Consumers)
+	}
+	return n
+}
+
+// AnalyzeSkew compares the shared modules of all consumers. It returns one
+// entry per module, most fragmented first: by incompatible release lines,
+// then distinct versions, then consumers. Modules without a version are
+// ignored.
+func AnalyzeSkew(consumers []Consumer) []ModuleSkew {
+	type usage struct {
+		singleton bool
+		versions  map[string][]string
+	}
+	modules := map[string]*usage{}
+	for _, c := range consumers {
+		for _, m := range c.Modules {
+			if m.Version == "" {
+				continue
+			}
+			u := modules[m.Name]
+			if u == nil {
+				u = &usage{versions: map[string][]string{}}
+				modules[m.Name] = u
+			}
+			u.singleton = u.singleton || m.Singleton
+			if users := u.versions[m.Version]; len(users) == 0 || users[len(users)-1] != c.Name {
+				u.versions[m.Version] = append(users, c.Name)
+			}
+		}
+	}
+
+	skew := make([]ModuleSkew, 0, len(modules))
+	for name, u := range modules {
+		s := ModuleSkew{Name: name, Singleton: u.singleton}
+		lines := map[string]bool{}
+		for version, users := range u.versions {
+			sort.Strings(users)
+			s.Versions = append(s.Versions, VersionUsage{Version: version, Consumers: users})
+			lines[releaseLine(version)] = true
+		}
+		sort.Slice(s.Versions, func(i, j int) bool {
+			return versionLess(s.Versions[i].Version, s.Versions[j].Version)
+		})
+		s.Lines = len(lines)
+		skew = append(skew, s)
+	}
+
+	sort.Slice(skew, func(i, j int) bool {
+		a, b := skew[i], skew[j]
+		switch {
+		case a.Lines != b.Lines:
+			return a.Lines > b.Lines
+		case len(a.Versions) != len(b.Versions):
+			return len(a.Versions) > len(b.Versions)
+		case a.Consumers() != b.Consumers():
+			return a.Consumers() > b.Consumers()
+		}
+		return a.Name < b.Name
+	})
+	return skew
+}
+
+// releaseLine returns the semver compatibility line of version, e.g. "18"
+// for 18.2.0 and "0.14" for 0.14.3. Unparseable versions are their own line.
+func releaseLine(version string) string {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return version
+	}
+	if v.Major() == 0 {
+		return fmt.Sprintf("0.%d", v.Minor())
+	}
+	return fmt.Sprintf("%d", v.Major())
+}
+
+// versionLess orders semver versions before unparseable ones.
+func versionLess(a, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.LessThan(vb)
+	case errA == nil || errB == nil:
+		return errA == nil
+	}
+	return a < b
+}
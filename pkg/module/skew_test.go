@@ -0,0 +1,58 @@
+// File: pkg/module/skew_test.go
+package module_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mfe-operator/pkg/module"
+)
+
+func TestAnalyzeSkew(t *testing.T) {
+	skew := module.AnalyzeSkew([]module.Consumer{
+		{Name: "shop/checkout", Modules: []module.SharedModule{
+			{Name: "react", Version: "18.2.0", Singleton: true},
+			{Name: "lodash", Version: "4.17.21"},
+			{Name: "zod", Version: "0.9.1"},
+		}},
+		{Name: "shop/catalog", Modules: []module.SharedModule{
+			{Name: "react", Version: "17.0.2", Singleton: true},
+			{Name: "lodash", Version: "4.17.20"},
+			{Name: "zod", Version: "0.10.0"},
+			{Name: "unversioned"},
+		}},
+		{Name: "shop/search", Modules: []module.SharedModule{
+			{Name: "react", Version: "18.3.1"},
+			{Name: "react", Version: "18.3.1"},
+			{Name: "lodash", Version: "4.17.21"},
+		}},
+	})
+
+	require.Len(t, skew, 3)
+	assert.Equal(t, module.ModuleSkew{
+		Name:      "react",
+		Singleton: true,
+		Versions: []module.VersionUsage{
+			{Version: "17.0.2", Consumers: []string{"shop/catalog"}},
+			{Version: "18.2.0", Consumers: []string{"shop/checkout"}},
+			{Version: "18.3.1", Consumers: []string{"shop/search"}},
+		},
+		Lines: 2,
+	}, skew[0])
+	assert.True(t, skew[0].Conflict())
+	assert.Equal(t, 3, skew[0].Consumers())
+
+	// 0.x minors are incompatible, but zod is not a singleton
+	assert.Equal(t, "zod", skew[1].Name)
+	assert.Equal(t, 2, skew[1].Lines)
+	assert.False(t, skew[1].Conflict())
+
+	assert.Equal(t, "lodash", skew[2].Name)
+	assert.Equal(t, 1, skew[2].Lines)
+	assert.Equal(t, []module.VersionUsage{
+		{Version: "4.17.20", Consumers: []string{"shop/catalog"}},
+		{Version: "4.17.21", Consumers: []string{"shop/checkout", "shop/search"}},
+	}, skew[2].Versions)
+}